@@ -0,0 +1,17 @@
+package promred
+
+import "context"
+
+type invokerKey struct{}
+
+// WithInvoker returns a copy of ctx carrying invoker, which labels the calls made with it.
+func WithInvoker(ctx context.Context, invoker string) context.Context {
+	return context.WithValue(ctx, invokerKey{}, invoker)
+}
+
+// InvokerFromContext returns the invoker carried by ctx, if there is one.
+func InvokerFromContext(ctx context.Context) (string, bool) {
+	invoker, ok := ctx.Value(invokerKey{}).(string)
+
+	return invoker, ok
+}
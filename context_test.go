@@ -0,0 +1,58 @@
+package promred
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRecorder_ObserveContext(t *testing.T) {
+	tests := []struct {
+		name            string
+		givenContext    context.Context
+		givenOptions    []Option
+		expectedInvoker string
+	}{
+		{
+			name:            "given invoker in context, expect it to label the operation",
+			givenContext:    WithInvoker(context.Background(), "checkout"),
+			expectedInvoker: "checkout",
+		},
+		{
+			name:            "given no invoker in context, expect unknown to label the operation",
+			givenContext:    context.Background(),
+			expectedInvoker: "unknown",
+		},
+		{
+			name:            "given no invoker in context and a default invoker, expect the default to label the operation",
+			givenContext:    context.Background(),
+			givenOptions:    []Option{WithDefaultInvoker("main")},
+			expectedInvoker: "main",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, append(test.givenOptions, WithRegisterer(reg))...)
+
+			_ = r.ObserveContext(test.givenContext, "operation", func() error {
+				return nil
+			})
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "test_operation_total", prometheus.Labels{
+				"invoker": test.expectedInvoker, "operation": "operation",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, 1) {
+				t.Fatal(cmp.Diff(actualOperationCount, 1))
+			}
+		})
+	}
+}
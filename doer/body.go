@@ -0,0 +1,102 @@
+package doer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jamieaitken/promred"
+)
+
+// bodies records how long response bodies take to be read and how large they are, and counts those which leak their
+// connection by being neither read to EOF nor closed.
+type bodies struct {
+	duration *promred.Histogram
+	size     *promred.Histogram
+	unclosed *promred.Counter
+}
+
+func newBodies(recorder *promred.Recorder, labels []string) *bodies {
+	return &bodies{
+		duration: recorder.Histogram("response_body_duration_seconds",
+			"The amount of time those requests take until their response bodies are read or closed", nil, labels...),
+		size: recorder.Histogram("response_body_size_bytes", "The number of bytes read from the response bodies of those requests",
+			defaultSizeBuckets, labels...),
+		unclosed: recorder.Counter("response_body_unclosed_total",
+			"The number of those requests whose response bodies were neither read nor closed", labels...),
+	}
+}
+
+// wrap replaces the body of res with one recording against lvs, where start is when the request was sent.
+func (b *bodies) wrap(ctx context.Context, res *http.Response, start time.Time, lvs []string) {
+	body := &responseBody{
+		ReadCloser: res.Body,
+		bodies:     b,
+		ctx:        ctx,
+		start:      start,
+		lvs:        lvs,
+	}
+
+	runtime.SetFinalizer(body, (*responseBody).finalize)
+
+	// The body of a 101 Switching Protocols response is also an io.Writer, which callers rely on.
+	if writer, ok := res.Body.(io.Writer); ok {
+		res.Body = struct {
+			*responseBody
+			io.Writer
+		}{body, writer}
+
+		return
+	}
+
+	res.Body = body
+}
+
+type responseBody struct {
+	io.ReadCloser
+	bodies   *bodies
+	ctx      context.Context
+	start    time.Time
+	lvs      []string
+	size     atomic.Int64
+	finished atomic.Bool
+	once     sync.Once
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size.Add(int64(n))
+
+	if errors.Is(err, io.EOF) {
+		b.finish()
+	}
+
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	b.finish()
+
+	return b.ReadCloser.Close()
+}
+
+func (b *responseBody) finish() {
+	b.once.Do(func() {
+		b.finished.Store(true)
+		b.bodies.duration.Observe(b.ctx, time.Since(b.start).Seconds(), b.lvs...)
+		b.bodies.size.Observe(b.ctx, float64(b.size.Load()), b.lvs...)
+	})
+}
+
+// finalize counts b as unclosed if it was garbage collected without being read to EOF or closed, as net/http only
+// returns a connection to its pool once its response body has been read to EOF or closed.
+func (b *responseBody) finalize() {
+	if !b.finished.Load() {
+		b.bodies.unclosed.Inc(b.ctx, b.lvs...)
+	}
+}
@@ -0,0 +1,130 @@
+package doer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNew_WithResponseBody(t *testing.T) {
+	tests := []struct {
+		name                  string
+		givenRead             bool
+		givenClose            bool
+		expectedSize          float64
+		expectedObservedCount int
+		expectedUnclosedCount int
+	}{
+		{
+			name:                  "given body read and closed, expect its size to be recorded and it not to be unclosed",
+			givenRead:             true,
+			givenClose:            true,
+			expectedSize:          5,
+			expectedObservedCount: 1,
+			expectedUnclosedCount: 0,
+		},
+		{
+			name:                  "given body closed without being read, expect a size of 0",
+			givenClose:            true,
+			expectedSize:          0,
+			expectedObservedCount: 1,
+			expectedUnclosedCount: 0,
+		},
+		{
+			name:                  "given body read to EOF but never closed, expect its size to be recorded and it not to be unclosed",
+			givenRead:             true,
+			expectedSize:          5,
+			expectedObservedCount: 1,
+			expectedUnclosedCount: 0,
+		},
+		{
+			name:                  "given body neither read nor closed, expect nothing to be recorded but it to be unclosed",
+			expectedSize:          0,
+			expectedObservedCount: 0,
+			expectedUnclosedCount: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			d := New(mockDoerFunc(func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("12345"))}, nil
+			}), WithRegisterer(reg), WithResponseBody())
+
+			func() {
+				res, err := d.Do(httptest.NewRequest(http.MethodGet, "https://example.com/test", nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if test.givenRead {
+					_, _ = io.ReadAll(res.Body)
+				}
+
+				if test.givenClose {
+					_ = res.Body.Close()
+				}
+			}()
+
+			actualObservedCount, err := testutil.GatherAndCount(reg, "doer_response_body_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualObservedCount, test.expectedObservedCount) {
+				t.Fatal(cmp.Diff(actualObservedCount, test.expectedObservedCount))
+			}
+
+			if test.expectedObservedCount > 0 {
+				actualHistogram, err := testtool.GetHistogram(reg, "doer_response_body_size_bytes")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(actualHistogram.GetSampleSum(), test.expectedSize) {
+					t.Fatal(cmp.Diff(actualHistogram.GetSampleSum(), test.expectedSize))
+				}
+			}
+
+			labels := prometheus.Labels{"path": "/test", "http_method": http.MethodGet}
+
+			var actualUnclosedCount int
+
+			// Unclosed bodies are only counted once a finalizer has run, which needs a garbage collection or two.
+			for i := 0; i < 50; i++ {
+				runtime.GC()
+
+				actualUnclosedCount, err = testtool.GetCounterValue(reg, "doer_response_body_unclosed_total", labels)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if actualUnclosedCount == test.expectedUnclosedCount && (test.expectedUnclosedCount > 0 || i > 2) {
+					break
+				}
+
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if !cmp.Equal(actualUnclosedCount, test.expectedUnclosedCount) {
+				t.Fatal(cmp.Diff(actualUnclosedCount, test.expectedUnclosedCount))
+			}
+		})
+	}
+}
+
+type mockDoerFunc func(req *http.Request) (*http.Response, error)
+
+func (m mockDoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return m(req)
+}
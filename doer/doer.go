@@ -1,65 +1,144 @@
 package doer
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/jamieaitken/promred"
 )
 
-var (
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
-)
-
-func init() {
-	operationCount = withRate()
-	errorCount = withError()
-	duration = withDuration()
-}
+var errNilResponse = errors.New("nil response")
 
 type doerProvider interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
 type Doer struct {
-	doerProvider   doerProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	doerProvider doerProvider
+	instrumenter instrumenter
 }
 
-func New(doer doerProvider) Doer {
+func New(doer doerProvider, opts ...Option) Doer {
 	return Doer{
-		doerProvider:   doer,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		doerProvider: doer,
+		instrumenter: newInstrumenter(opts),
 	}
 }
 
 func (d Doer) Do(req *http.Request) (*http.Response, error) {
-	timer := prometheus.NewTimer(d.duration.WithLabelValues(req.URL.Path, req.Method))
-	defer timer.ObserveDuration()
+	return d.instrumenter.do(req, d.doerProvider.Do)
+}
 
-	d.operationCount.WithLabelValues(req.URL.Path, req.Method).Inc()
+// noStatus labels requests which failed without a response.
+const noStatus = "none"
 
-	res, err := d.doerProvider.Do(req)
-	if err != nil {
-		d.errorCount.WithLabelValues(req.URL.Path, req.Method).Inc()
+// instrumenter records the requests sent by a Doer or Transport.
+type instrumenter struct {
+	recorder        *promred.Recorder
+	phases          *phases
+	bodies          *bodies
+	host            bool
+	pathNormalizers []PathNormalizer
+	statusLabel     func(statusCode int) string
+}
+
+func newInstrumenter(opts []Option) instrumenter {
+	o := newOptions(opts)
+
+	f, statusLabel := family, statusCodeLabel
+	if o.statusClass {
+		f.ResultLabels, statusLabel = []string{"status_class"}, statusClassLabel
+	}
+
+	if o.host {
+		f.Labels = append([]string{"host"}, f.Labels...)
+	}
+
+	recorder := promred.NewRecorder(f, o.recorder...)
+
+	var p *phases
+	if o.connectionTrace {
+		p = newPhases(recorder, f.Labels)
+	}
+
+	var b *bodies
+	if o.responseBody {
+		b = newBodies(recorder, f.Labels)
+	}
+
+	return instrumenter{
+		recorder:        recorder,
+		phases:          p,
+		bodies:          b,
+		host:            o.host,
+		pathNormalizers: o.pathNormalizers,
+		statusLabel:     statusLabel,
+	}
+}
+
+func (i instrumenter) do(req *http.Request, send func(req *http.Request) (*http.Response, error)) (*http.Response, error) {
+	lvs := i.labelValues(req)
+
+	done := i.recorder.InFlight(lvs...)
+	defer done()
+
+	start := time.Now()
+
+	if i.phases != nil {
+		req = i.phases.trace(req, start, lvs)
+	}
+
+	res, err := send(req)
+
+	if i.bodies != nil && res != nil && res.Body != nil {
+		i.bodies.wrap(req.Context(), res, start, lvs)
+	}
+
+	i.recorder.Record(req.Context(), time.Since(start), resultError(res, err), append(lvs, i.status(res))...)
+
+	return res, err
+}
+
+func (i instrumenter) labelValues(req *http.Request) []string {
+	path := pathFor(req.URL.Path, i.pathNormalizers)
 
-		return res, err
+	if i.host {
+		return []string{req.URL.Host, path, req.Method}
 	}
 
+	return []string{path, req.Method}
+}
+
+func (i instrumenter) status(res *http.Response) string {
 	if res == nil {
-		d.errorCount.WithLabelValues(req.URL.Path, req.Method).Inc()
+		return noStatus
+	}
 
-		return res, err
+	return i.statusLabel(res.StatusCode)
+}
+
+func statusCodeLabel(statusCode int) string {
+	return fmt.Sprint(statusCode)
+}
+
+func statusClassLabel(statusCode int) string {
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
+
+func resultError(res *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if res == nil {
+		return errNilResponse
 	}
 
 	if res.StatusCode >= 400 {
-		d.errorCount.WithLabelValues(req.URL.Path, req.Method).Inc()
+		return &promred.StatusError{StatusCode: res.StatusCode}
 	}
 
-	return res, err
+	return nil
 }
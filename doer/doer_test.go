@@ -2,12 +2,16 @@ package doer
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
 	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestDoer_Do_Success(t *testing.T) {
@@ -15,6 +19,7 @@ func TestDoer_Do_Success(t *testing.T) {
 		name                   string
 		givenDoer              doerProvider
 		givenRequest           *http.Request
+		expectedStatusCode     string
 		expectedOperationCount int
 		expectedErrorCount     int
 	}{
@@ -34,6 +39,7 @@ func TestDoer_Do_Success(t *testing.T) {
 					Path:   "/test",
 				},
 			},
+			expectedStatusCode:     "200",
 			expectedErrorCount:     0,
 			expectedOperationCount: 1,
 		},
@@ -47,7 +53,9 @@ func TestDoer_Do_Success(t *testing.T) {
 				t.Fatalf("expected nil, got %v", err)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*doer.operationCount, test.givenRequest.URL.Path, test.givenRequest.Method)
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "doer_operation_total", prometheus.Labels{
+				"path": test.givenRequest.URL.Path, "http_method": test.givenRequest.Method, "status_code": test.expectedStatusCode,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -56,7 +64,9 @@ func TestDoer_Do_Success(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*doer.errorCount, test.givenRequest.URL.Path, test.givenRequest.Method)
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "doer_error_total", prometheus.Labels{
+				"path": test.givenRequest.URL.Path, "http_method": test.givenRequest.Method, "status_code": test.expectedStatusCode,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -74,11 +84,12 @@ func TestDoer_Do_Fail(t *testing.T) {
 		givenDoer              doerProvider
 		givenRequest           *http.Request
 		expectError            bool
+		expectedStatusCode     string
 		expectedOperationCount int
 		expectedErrorCount     int
 	}{
 		{
-			name: "given doer fail, expect operation count to be 2 and error count to be 1",
+			name: "given doer fail, expect operation count to be 1 and error count to be 1 without a status",
 			givenDoer: mockDoer{
 				GivenError: errors.New("fail"),
 			},
@@ -91,11 +102,12 @@ func TestDoer_Do_Fail(t *testing.T) {
 				},
 			},
 			expectError:            true,
+			expectedStatusCode:     "none",
 			expectedErrorCount:     1,
-			expectedOperationCount: 2,
+			expectedOperationCount: 1,
 		},
 		{
-			name:      "given nil response, expect operation count to be 3 and error count to be 2",
+			name:      "given nil response, expect operation count to be 2 and error count to be 2 without a status",
 			givenDoer: mockDoer{},
 			givenRequest: &http.Request{
 				Method: http.MethodGet,
@@ -106,11 +118,12 @@ func TestDoer_Do_Fail(t *testing.T) {
 				},
 			},
 			expectError:            false,
+			expectedStatusCode:     "none",
 			expectedErrorCount:     2,
-			expectedOperationCount: 3,
+			expectedOperationCount: 2,
 		},
 		{
-			name: "given response of 404, expect operation count to be 4 and error count to be 3",
+			name: "given response of 404, expect operation count to be 1 and error count to be 1",
 			givenDoer: mockDoer{
 				GivenResponse: &http.Response{
 					Status:     http.StatusText(http.StatusNotFound),
@@ -126,8 +139,9 @@ func TestDoer_Do_Fail(t *testing.T) {
 				},
 			},
 			expectError:            false,
-			expectedErrorCount:     3,
-			expectedOperationCount: 4,
+			expectedStatusCode:     "404",
+			expectedErrorCount:     1,
+			expectedOperationCount: 1,
 		},
 	}
 	for _, test := range tests {
@@ -139,7 +153,9 @@ func TestDoer_Do_Fail(t *testing.T) {
 				t.Fatalf("got nil")
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*doer.operationCount, test.givenRequest.URL.Path, test.givenRequest.Method)
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "doer_operation_total", prometheus.Labels{
+				"path": test.givenRequest.URL.Path, "http_method": test.givenRequest.Method, "status_code": test.expectedStatusCode,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -148,7 +164,103 @@ func TestDoer_Do_Fail(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*doer.errorCount, test.givenRequest.URL.Path, test.givenRequest.Method)
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "doer_error_total", prometheus.Labels{
+				"path": test.givenRequest.URL.Path, "http_method": test.givenRequest.Method, "status_code": test.expectedStatusCode,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNew_WithInFlight(t *testing.T) {
+	tests := []struct {
+		name              string
+		givenPanic        bool
+		expectedAfterCall int
+	}{
+		{
+			name:              "given a successful call, expect the gauge to be 0 after",
+			expectedAfterCall: 0,
+		},
+		{
+			name:              "given the doer panics and is recovered upstream, expect the gauge to be 0 after",
+			givenPanic:        true,
+			expectedAfterCall: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			d := New(mockDoerFunc(func(_ *http.Request) (*http.Response, error) {
+				if test.givenPanic {
+					panic("fail")
+				}
+
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}), WithRegisterer(reg), WithInFlight())
+
+			func() {
+				defer func() {
+					_ = recover()
+				}()
+
+				_, _ = d.Do(httptest.NewRequest(http.MethodGet, "/test", nil))
+			}()
+
+			labels := prometheus.Labels{"path": "/test", "http_method": http.MethodGet}
+
+			actualAfterCall, err := testtool.GetGaugeValue(reg, "doer_in_flight", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualAfterCall, test.expectedAfterCall) {
+				t.Fatal(cmp.Diff(actualAfterCall, test.expectedAfterCall))
+			}
+		})
+	}
+}
+
+func TestNew_WithErrorClassifier(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenStatusCode    int
+		expectedErrorCount int
+	}{
+		{
+			name:               "given response of 404, expect error count to be 0",
+			givenStatusCode:    http.StatusNotFound,
+			expectedErrorCount: 0,
+		},
+		{
+			name:               "given response of 503, expect error count to be 1",
+			givenStatusCode:    http.StatusServiceUnavailable,
+			expectedErrorCount: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			d := New(mockDoer{GivenResponse: &http.Response{StatusCode: test.givenStatusCode}}, WithRegisterer(reg),
+				WithErrorClassifier(func(err error) bool {
+					var statusErr *promred.StatusError
+
+					return !errors.As(err, &statusErr) || statusErr.StatusCode >= 500
+				}))
+
+			_, _ = d.Do(httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "doer_error_total", prometheus.Labels{
+				"path": "/test", "http_method": http.MethodGet, "status_code": fmt.Sprint(test.givenStatusCode),
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -168,3 +280,142 @@ type mockDoer struct {
 func (m mockDoer) Do(_ *http.Request) (*http.Response, error) {
 	return m.GivenResponse, m.GivenError
 }
+
+func TestNew_WithHostAndStatusClass(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenOptions   []Option
+		givenResponse  *http.Response
+		expectedLabels prometheus.Labels
+	}{
+		{
+			name:           "given host, expect the host to be labelled",
+			givenOptions:   []Option{WithHost()},
+			givenResponse:  &http.Response{StatusCode: http.StatusTooManyRequests},
+			expectedLabels: prometheus.Labels{"host": "example.com", "path": "/test", "http_method": http.MethodGet, "status_code": "429"},
+		},
+		{
+			name:           "given status class, expect the status class to be labelled",
+			givenOptions:   []Option{WithStatusClass()},
+			givenResponse:  &http.Response{StatusCode: http.StatusServiceUnavailable},
+			expectedLabels: prometheus.Labels{"path": "/test", "http_method": http.MethodGet, "status_class": "5xx"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			d := New(mockDoer{GivenResponse: test.givenResponse}, append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			_, err := d.Do(httptest.NewRequest(http.MethodGet, "https://example.com/test", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "doer_operation_total", test.expectedLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, 1) {
+				t.Fatal(cmp.Diff(actualOperationCount, 1))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "doer_error_total", test.expectedLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, 1) {
+				t.Fatal(cmp.Diff(actualErrorCount, 1))
+			}
+		})
+	}
+}
+
+func TestNew_WithPathNormalizer(t *testing.T) {
+	tests := []struct {
+		name              string
+		givenNormalizers  []PathNormalizer
+		givenPath         string
+		expectedPathLabel string
+	}{
+		{
+			name:              "given no normalizers, expect the URL path",
+			givenPath:         "/orders/123/items",
+			expectedPathLabel: "/orders/123/items",
+		},
+		{
+			name:              "given a matching template, expect the template",
+			givenNormalizers:  []PathNormalizer{PathTemplates("/orders/{id}", "/orders/{id}/items")},
+			givenPath:         "/orders/8f3a2c1e-5b7d-4e9f-a1b2-c3d4e5f6a7b8/items",
+			expectedPathLabel: "/orders/{id}/items",
+		},
+		{
+			name:              "given a template with a literal segment that differs, expect unmatched",
+			givenNormalizers:  []PathNormalizer{PathTemplates("/orders/{id}/items")},
+			givenPath:         "/orders/123/payments",
+			expectedPathLabel: UnmatchedPath,
+		},
+		{
+			name:              "given a template and an empty segment, expect unmatched",
+			givenNormalizers:  []PathNormalizer{PathTemplates("/orders/{id}/items")},
+			givenPath:         "/orders//items",
+			expectedPathLabel: UnmatchedPath,
+		},
+		{
+			name:              "given no matching template then placeholders, expect placeholders",
+			givenNormalizers:  []PathNormalizer{PathTemplates("/orders/{id}/items"), PathPlaceholders},
+			givenPath:         "/users/42/orders",
+			expectedPathLabel: "/users/{id}/orders",
+		},
+		{
+			name:              "given placeholders and a UUID, expect uuid",
+			givenNormalizers:  []PathNormalizer{PathPlaceholders},
+			givenPath:         "/orders/8F3A2C1E-5B7D-4E9F-A1B2-C3D4E5F6A7B8/items",
+			expectedPathLabel: "/orders/{uuid}/items",
+		},
+		{
+			name:              "given placeholders and a hash, expect hash",
+			givenNormalizers:  []PathNormalizer{PathPlaceholders},
+			givenPath:         "/commits/3f786850e387550fdab836ed7e6dc881de23001b",
+			expectedPathLabel: "/commits/{hash}",
+		},
+		{
+			name:              "given placeholders and a long token, expect token",
+			givenNormalizers:  []PathNormalizer{PathPlaceholders},
+			givenPath:         "/invites/aGVsbG8td29ybGQ_MTIzNDU2Nzg5MA/accept",
+			expectedPathLabel: "/invites/{token}/accept",
+		},
+		{
+			name:              "given placeholders and words, expect them to be kept",
+			givenNormalizers:  []PathNormalizer{PathPlaceholders},
+			givenPath:         "/v1/create-customer-subscription-schedule/abc",
+			expectedPathLabel: "/v1/create-customer-subscription-schedule/abc",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			d := New(mockDoer{GivenResponse: &http.Response{StatusCode: http.StatusOK}}, WithRegisterer(reg),
+				WithPathNormalizer(test.givenNormalizers...))
+
+			_, err := d.Do(httptest.NewRequest(http.MethodGet, "https://example.com"+test.givenPath, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := prometheus.Labels{"path": test.expectedPathLabel, "http_method": http.MethodGet, "status_code": "200"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "doer_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, 1) {
+				t.Fatal(cmp.Diff(actualOperationCount, 1))
+			}
+		})
+	}
+}
@@ -1,40 +1,15 @@
 package doer
 
-import "github.com/prometheus/client_golang/prometheus"
-
-var (
-	labels = []string{"path", "http_method"}
+import (
+	"github.com/jamieaitken/promred"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func withRate() *prometheus.CounterVec {
-	r := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "doer_operation_total",
-		Help: "The number of requests",
-	}, labels)
-
-	prometheus.MustRegister(r)
-
-	return r
-}
-
-func withError() *prometheus.CounterVec {
-	r := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "doer_error_total",
-		Help: "The number of those requests that have failed",
-	}, labels)
-
-	prometheus.MustRegister(r)
-
-	return r
+var family = promred.Family{
+	Name:         "doer",
+	Noun:         "requests",
+	Labels:       []string{"path", "http_method"},
+	ResultLabels: []string{"status_code"},
 }
 
-func withDuration() *prometheus.HistogramVec {
-	d := prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name: "doer_duration_seconds",
-		Help: "The amount of time those requests take",
-	}, labels)
-
-	prometheus.MustRegister(d)
-
-	return d
-}
+var defaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 8)
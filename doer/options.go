@@ -0,0 +1,147 @@
+package doer
+
+import (
+	"context"
+
+	"github.com/jamieaitken/promred"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Option configures the metrics created by New.
+type Option func(*options)
+
+type options struct {
+	recorder        []promred.Option
+	statusClass     bool
+	host            bool
+	connectionTrace bool
+	responseBody    bool
+	pathNormalizers []PathNormalizer
+}
+
+// WithRegisterer registers the doer metrics with reg rather than prometheus.DefaultRegisterer. See
+// promred.WithRegisterer for sharing reg between constructors.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return withRecorderOption(promred.WithRegisterer(reg))
+}
+
+// WithMeterProvider also emits the doer metrics as OpenTelemetry instruments created from provider. See
+// promred.WithMeterProvider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return withRecorderOption(promred.WithMeterProvider(provider))
+}
+
+// WithNamespace prefixes the name of each doer metric with namespace. See promred.WithNamespace.
+func WithNamespace(namespace string) Option {
+	return withRecorderOption(promred.WithNamespace(namespace))
+}
+
+// WithSubsystem prefixes the name of each doer metric with subsystem, after any namespace. See promred.WithSubsystem.
+func WithSubsystem(subsystem string) Option {
+	return withRecorderOption(promred.WithSubsystem(subsystem))
+}
+
+// WithConstLabels adds labels with fixed values, such as service or env, to each doer metric. See
+// promred.WithConstLabels.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return withRecorderOption(promred.WithConstLabels(labels))
+}
+
+// WithBuckets sets the upper bounds of the buckets of every duration histogram, in seconds, including those of the
+// connection phases, time to first byte and response bodies. It defaults to prometheus.DefBuckets. The response body
+// size histogram keeps buckets of its own. See promred.WithBuckets.
+func WithBuckets(buckets []float64) Option {
+	return withRecorderOption(promred.WithBuckets(buckets))
+}
+
+// WithNativeHistogramBucketFactor exposes every histogram, of the request, its connection phases, time to first byte
+// and response bodies alike, as a native histogram, where factor is the maximum growth from one bucket to the next,
+// e.g. 1.1. Classic buckets are only kept alongside it if WithBuckets is given, other than those of the response body
+// size histogram, which are always kept. See promred.WithNativeHistogramBucketFactor.
+func WithNativeHistogramBucketFactor(factor float64) Option {
+	return withRecorderOption(promred.WithNativeHistogramBucketFactor(factor))
+}
+
+// WithTraceID sets how the trace ID attached to the doer metrics as an exemplar is read from a context. See
+// promred.WithTraceID.
+func WithTraceID(traceID func(ctx context.Context) (string, bool)) Option {
+	return withRecorderOption(promred.WithTraceID(traceID))
+}
+
+// WithErrorClassifier sets which errors count towards doer_error_total, defaulting to promred.IsError. See
+// promred.WithErrorClassifier.
+func WithErrorClassifier(isError func(err error) bool) Option {
+	return withRecorderOption(promred.WithErrorClassifier(isError))
+}
+
+// WithErrorReason adds a reason label to doer_error_total, trying mappers in turn before promred.Reason. See
+// promred.WithErrorReason.
+func WithErrorReason(mappers ...promred.ReasonMapper) Option {
+	return withRecorderOption(promred.WithErrorReason(mappers...))
+}
+
+// WithInFlight adds a doer_in_flight gauge of the calls currently being made, labelled by path and http_method, after
+// host if WithHost is given. See promred.WithInFlight.
+func WithInFlight() Option {
+	return withRecorderOption(promred.WithInFlight())
+}
+
+// WithStatusClass labels requests by the class of their response status, such as 2xx or 5xx, in a status_class label
+// rather than by their status code, to cut the number of series. It changes labels, so must be given to every
+// constructor sharing a registerer, or none.
+func WithStatusClass() Option {
+	return func(o *options) {
+		o.statusClass = true
+	}
+}
+
+// WithHost also labels each metric with the host a request was sent to, so that calls to different hosts with the same
+// path can be told apart. It changes labels, so must be given to every constructor sharing a registerer, or none.
+func WithHost() Option {
+	return func(o *options) {
+		o.host = true
+	}
+}
+
+// WithConnectionTrace traces each request to record how long its DNS lookup, connection, TLS handshake and wait for
+// the first byte of its response take, in histograms labelled like doer_duration_seconds.
+func WithConnectionTrace() Option {
+	return func(o *options) {
+		o.connectionTrace = true
+	}
+}
+
+// WithResponseBody records how long each response body takes to be read to EOF or closed, counted from when its
+// request was sent, and how many bytes were read from it. Bodies which are neither read to EOF nor closed, leaking
+// their connection, are counted in doer_response_body_unclosed_total once they are garbage collected.
+func WithResponseBody() Option {
+	return func(o *options) {
+		o.responseBody = true
+	}
+}
+
+// WithPathNormalizer labels the path of each request with the first of normalizers able to normalise its URL path, so
+// that calls such as GET /orders/{id}/items do not create a series per ID. Requests none of them can normalise are
+// labelled UnmatchedPath.
+func WithPathNormalizer(normalizers ...PathNormalizer) Option {
+	return func(o *options) {
+		o.pathNormalizers = normalizers
+	}
+}
+
+func withRecorderOption(opt promred.Option) Option {
+	return func(o *options) {
+		o.recorder = append(o.recorder, opt)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -0,0 +1,105 @@
+package doer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// UnmatchedPath labels requests whose path none of the PathNormalizers given to WithPathNormalizer could normalise.
+const UnmatchedPath = "unmatched"
+
+// PathNormalizer returns the value of the path label for a request's URL path, or false if it cannot normalise path.
+type PathNormalizer func(path string) (string, bool)
+
+// PathTemplates normalises paths matching one of templates, such as /orders/{id}/items, to that template, trying each
+// in turn. A segment in braces matches any single non-empty segment, and every other segment only itself.
+func PathTemplates(templates ...string) PathNormalizer {
+	segments := make([][]string, len(templates))
+	for i, template := range templates {
+		segments[i] = strings.Split(template, "/")
+	}
+
+	return func(path string) (string, bool) {
+		pathSegments := strings.Split(path, "/")
+
+		for i, templateSegments := range segments {
+			if matchesTemplate(pathSegments, templateSegments) {
+				return templates[i], true
+			}
+		}
+
+		return "", false
+	}
+}
+
+func matchesTemplate(pathSegments, templateSegments []string) bool {
+	if len(pathSegments) != len(templateSegments) {
+		return false
+	}
+
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return false
+			}
+
+			continue
+		}
+
+		if pathSegments[i] != segment {
+			return false
+		}
+	}
+
+	return true
+}
+
+var (
+	uuidSegment  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	idSegment    = regexp.MustCompile(`^[0-9]+$`)
+	hashSegment  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+	tokenSegment = regexp.MustCompile(`^[0-9A-Za-z_-]{20,}$`)
+)
+
+// PathPlaceholders normalises every path by replacing segments which look like identifiers with placeholders: UUIDs
+// with {uuid}, numbers with {id}, hexadecimal strings of 16 or more characters, such as hashes, with {hash}, and other
+// tokens of 20 or more letters, digits, underscores and hyphens, which include a digit, with {token}. Given last to
+// WithPathNormalizer, it replaces UnmatchedPath.
+func PathPlaceholders(path string) (string, bool) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = placeholderFor(segment)
+	}
+
+	return strings.Join(segments, "/"), true
+}
+
+func placeholderFor(segment string) string {
+	switch {
+	case uuidSegment.MatchString(segment):
+		return "{uuid}"
+	case idSegment.MatchString(segment):
+		return "{id}"
+	case hashSegment.MatchString(segment):
+		return "{hash}"
+	case tokenSegment.MatchString(segment) && strings.ContainsAny(segment, "0123456789"):
+		return "{token}"
+	default:
+		return segment
+	}
+}
+
+func pathFor(path string, normalizers []PathNormalizer) string {
+	if normalizers == nil {
+		return path
+	}
+
+	for _, normalizer := range normalizers {
+		normalized, ok := normalizer(path)
+		if ok {
+			return normalized
+		}
+	}
+
+	return UnmatchedPath
+}
@@ -0,0 +1,91 @@
+package doer
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/jamieaitken/promred"
+)
+
+// phases records how long each phase of sending a request takes, through an httptrace.ClientTrace.
+type phases struct {
+	dns       *promred.Histogram
+	connect   *promred.Histogram
+	tls       *promred.Histogram
+	firstByte *promred.Histogram
+}
+
+func newPhases(recorder *promred.Recorder, labels []string) *phases {
+	return &phases{
+		dns:       recorder.Histogram("dns_duration_seconds", "The amount of time the DNS lookups of those requests take", nil, labels...),
+		connect:   recorder.Histogram("connect_duration_seconds", "The amount of time the connections of those requests take", nil, labels...),
+		tls:       recorder.Histogram("tls_duration_seconds", "The amount of time the TLS handshakes of those requests take", nil, labels...),
+		firstByte: recorder.Histogram("time_to_first_byte_seconds", "The amount of time those requests take to get a response", nil, labels...),
+	}
+}
+
+// trace returns req with a trace attached which records each phase against lvs, where start is when req was sent.
+func (p *phases) trace(req *http.Request, start time.Time, lvs []string) *http.Request {
+	ctx := req.Context()
+
+	var (
+		mu            sync.Mutex
+		dnsStart      time.Time
+		tlsStart      time.Time
+		connectStarts = map[string]time.Time{}
+	)
+
+	since := func(t *time.Time) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return time.Since(*t)
+	}
+
+	mark := func(t *time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		*t = time.Now()
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(_ httptrace.DNSStartInfo) {
+			mark(&dnsStart)
+		},
+		DNSDone: func(_ httptrace.DNSDoneInfo) {
+			p.dns.Observe(ctx, since(&dnsStart).Seconds(), lvs...)
+		},
+		// Connections to several addresses can be raced, so each is timed separately, and only those which succeed are
+		// recorded, as a failed dial says nothing of how long connecting takes.
+		ConnectStart: func(network, addr string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			connectStarts[network+addr] = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				return
+			}
+
+			mu.Lock()
+			connectStart := connectStarts[network+addr]
+			mu.Unlock()
+
+			p.connect.Observe(ctx, time.Since(connectStart).Seconds(), lvs...)
+		},
+		TLSHandshakeStart: func() {
+			mark(&tlsStart)
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			p.tls.Observe(ctx, since(&tlsStart).Seconds(), lvs...)
+		},
+		GotFirstResponseByte: func() {
+			p.firstByte.Observe(ctx, time.Since(start).Seconds(), lvs...)
+		},
+	}))
+}
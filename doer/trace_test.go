@@ -0,0 +1,117 @@
+package doer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNew_WithConnectionTrace(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenOptions   []Option
+		givenHost      string
+		givenClosed    bool
+		expectedCounts map[string]int
+	}{
+		{
+			name:         "given TLS server by IP, expect connect, TLS and first byte but no DNS lookup",
+			givenOptions: []Option{WithConnectionTrace()},
+			expectedCounts: map[string]int{
+				"doer_dns_duration_seconds":       0,
+				"doer_connect_duration_seconds":   1,
+				"doer_tls_duration_seconds":       1,
+				"doer_time_to_first_byte_seconds": 1,
+			},
+		},
+		{
+			name:         "given TLS server by hostname, expect a DNS lookup too",
+			givenOptions: []Option{WithConnectionTrace()},
+			givenHost:    "localhost",
+			expectedCounts: map[string]int{
+				"doer_dns_duration_seconds":       1,
+				"doer_connect_duration_seconds":   1,
+				"doer_tls_duration_seconds":       1,
+				"doer_time_to_first_byte_seconds": 1,
+			},
+		},
+		{
+			name:         "given closed server, expect no connect as the dial failed",
+			givenOptions: []Option{WithConnectionTrace()},
+			givenClosed:  true,
+			expectedCounts: map[string]int{
+				"doer_dns_duration_seconds":       0,
+				"doer_connect_duration_seconds":   0,
+				"doer_tls_duration_seconds":       0,
+				"doer_time_to_first_byte_seconds": 0,
+			},
+		},
+		{
+			name: "given no connection trace, expect no phases",
+			expectedCounts: map[string]int{
+				"doer_dns_duration_seconds":       0,
+				"doer_connect_duration_seconds":   0,
+				"doer_tls_duration_seconds":       0,
+				"doer_time_to_first_byte_seconds": 0,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := server.Client()
+			client.Transport.(*http.Transport).TLSClientConfig.ServerName = "example.com"
+
+			u, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.givenHost != "" {
+				u.Host = test.givenHost + ":" + u.Port()
+			}
+
+			d := New(client, append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			req, err := http.NewRequest(http.MethodGet, u.String()+"/v1/code", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.givenClosed {
+				server.Close()
+			}
+
+			res, err := d.Do(req)
+			if err != nil && !test.givenClosed {
+				t.Fatal(err)
+			}
+
+			if err == nil {
+				_ = res.Body.Close()
+			}
+
+			for name, expectedCount := range test.expectedCounts {
+				actualCount, err := testutil.GatherAndCount(reg, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(actualCount, expectedCount) {
+					t.Fatal(name, cmp.Diff(actualCount, expectedCount))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package doer
+
+import "net/http"
+
+var _ http.RoundTripper = Transport{}
+
+// Transport is an http.RoundTripper recording the same metrics as a Doer, for clients which only accept a
+// Transport, such as those of SDKs.
+type Transport struct {
+	base         http.RoundTripper
+	instrumenter instrumenter
+}
+
+// NewTransport instruments base, or http.DefaultTransport if base is nil.
+func NewTransport(base http.RoundTripper, opts ...Option) Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return Transport{
+		base:         base,
+		instrumenter: newInstrumenter(opts),
+	}
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.instrumenter.do(req, t.base.RoundTrip)
+}
+
+// CloseIdleConnections closes the idle connections of base, if it has any to close, so that http.Client's method of the
+// same name reaches it.
+func (t Transport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
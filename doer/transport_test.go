@@ -0,0 +1,164 @@
+package doer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenStatusCode        int
+		givenServerClosed      bool
+		expectedStatusCode     string
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given 200, expect operation count to be 1 and error count to be 0",
+			givenStatusCode:        http.StatusOK,
+			expectedStatusCode:     "200",
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:                   "given 503, expect operation count to be 1 and error count to be 1",
+			givenStatusCode:        http.StatusServiceUnavailable,
+			expectedStatusCode:     "503",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name:                   "given server closed, expect operation count to be 1 and error count to be 1",
+			givenServerClosed:      true,
+			expectedStatusCode:     "none",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.givenStatusCode)
+			}))
+			defer server.Close()
+
+			if test.givenServerClosed {
+				server.Close()
+			}
+
+			client := &http.Client{Transport: NewTransport(nil, WithRegisterer(reg))}
+
+			res, err := client.Get(server.URL + "/v1/code")
+			if err == nil {
+				_ = res.Body.Close()
+			}
+
+			labels := prometheus.Labels{"path": "/v1/code", "http_method": http.MethodGet, "status_code": test.expectedStatusCode}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "doer_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "doer_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestTransport_CloseIdleConnections(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenBase      http.RoundTripper
+		expectedClosed bool
+	}{
+		{
+			name:           "given a base with idle connections, expect them to be closed",
+			givenBase:      &mockIdleTransport{},
+			expectedClosed: true,
+		},
+		{
+			name:      "given a base without idle connections, expect nothing to happen",
+			givenBase: struct{ http.RoundTripper }{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &http.Client{Transport: NewTransport(test.givenBase, WithRegisterer(prometheus.NewRegistry()))}
+
+			client.CloseIdleConnections()
+
+			base, ok := test.givenBase.(*mockIdleTransport)
+			if !ok {
+				return
+			}
+
+			if !cmp.Equal(base.closed, test.expectedClosed) {
+				t.Fatal(cmp.Diff(base.closed, test.expectedClosed))
+			}
+		})
+	}
+}
+
+type mockIdleTransport struct {
+	http.RoundTripper
+	closed bool
+}
+
+func (m *mockIdleTransport) CloseIdleConnections() {
+	m.closed = true
+}
+
+func TestNewTransport_ConflictingLabels(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenOptions  []Option
+		expectedPanic string
+	}{
+		{
+			name:          "given a Doer with host and a Transport without on one registerer, expect a panic naming the options",
+			givenOptions:  []Option{WithHost()},
+			expectedPanic: "WithHost",
+		},
+		{
+			name:          "given a Doer with status class and a Transport without on one registerer, expect a panic naming the options",
+			givenOptions:  []Option{WithStatusClass()},
+			expectedPanic: "WithStatusClass",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			defer func() {
+				err, _ := recover().(error)
+
+				if err == nil || !strings.Contains(err.Error(), test.expectedPanic) {
+					t.Fatalf("expected a panic containing %q, got %v", test.expectedPanic, err)
+				}
+			}()
+
+			New(mockDoer{}, append(test.givenOptions, WithRegisterer(reg))...)
+			NewTransport(nil, WithRegisterer(reg))
+		})
+	}
+}
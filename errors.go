@@ -0,0 +1,22 @@
+package promred
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// StatusError is recorded for an HTTP response with a status code of 400 or above.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code %d", e.StatusCode)
+}
+
+// IsError is the default error classifier, under which every error other than a cancelled context counts as a
+// failure.
+func IsError(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
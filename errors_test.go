@@ -0,0 +1,112 @@
+package promred
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestIsError(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenError    error
+		expectedError bool
+	}{
+		{
+			name:          "given error, expect error",
+			givenError:    errFail,
+			expectedError: true,
+		},
+		{
+			name:          "given status error, expect error",
+			givenError:    &StatusError{StatusCode: 500},
+			expectedError: true,
+		},
+		{
+			name:          "given cancelled context, expect no error",
+			givenError:    context.Canceled,
+			expectedError: false,
+		},
+		{
+			name:          "given wrapped cancelled context, expect no error",
+			givenError:    fmt.Errorf("read: %w", context.Canceled),
+			expectedError: false,
+		},
+		{
+			name:          "given exceeded deadline, expect error",
+			givenError:    context.DeadlineExceeded,
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualError := IsError(test.givenError)
+
+			if !cmp.Equal(actualError, test.expectedError) {
+				t.Fatal(cmp.Diff(actualError, test.expectedError))
+			}
+		})
+	}
+}
+
+func TestRecorder_Record_WithErrorClassifier(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenClassifier    func(err error) bool
+		givenError         error
+		expectedErrorCount int
+	}{
+		{
+			name:               "given default classifier and cancelled context, expect error count to be 0",
+			givenError:         context.Canceled,
+			expectedErrorCount: 0,
+		},
+		{
+			name: "given classifier ignoring an error, expect error count to be 0",
+			givenClassifier: func(err error) bool {
+				return !errors.Is(err, errFail)
+			},
+			givenError:         errFail,
+			expectedErrorCount: 0,
+		},
+		{
+			name: "given classifier counting every error, expect error count to be 1",
+			givenClassifier: func(_ error) bool {
+				return true
+			},
+			givenError:         context.Canceled,
+			expectedErrorCount: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			opts := []Option{WithRegisterer(reg)}
+
+			if test.givenClassifier != nil {
+				opts = append(opts, WithErrorClassifier(test.givenClassifier))
+			}
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, opts...)
+
+			r.Record(context.Background(), time.Millisecond, test.givenError, "invoker", "operation")
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "test_error_total", prometheus.Labels{
+				"invoker": "invoker", "operation": "operation",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"github.com/jamieaitken/promred"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ClientAndServerErrors counts every status code other than OK as an error, except Canceled, which like a cancelled
+// context is the client going away. It is what WithErrorStatus defaults to.
+func ClientAndServerErrors(code codes.Code) bool {
+	return code != codes.OK && code != codes.Canceled
+}
+
+// ServerErrors counts only the status codes which are the server's fault as errors, such as Internal and Unavailable,
+// for SLOs which do not hold clients' mistakes against a service.
+func ServerErrors(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reason is the built-in promred.ReasonMapper for gRPC, which maps status codes for timeouts, cancellations,
+// exhausted resources and missing entities.
+func Reason(err error) (string, bool) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return "", false
+	}
+
+	switch s.Code() {
+	case codes.DeadlineExceeded:
+		return promred.ReasonTimeout, true
+	case codes.Canceled:
+		return promred.ReasonCanceled, true
+	case codes.ResourceExhausted:
+		return promred.ReasonThrottled, true
+	case codes.NotFound:
+		return promred.ReasonNotFound, true
+	default:
+		return "", false
+	}
+}
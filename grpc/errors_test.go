@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    codes.Code
+		expected bool
+	}{
+		{
+			name:     "given Internal, expect an error",
+			given:    codes.Internal,
+			expected: true,
+		},
+		{
+			name:     "given InvalidArgument, expect no error",
+			given:    codes.InvalidArgument,
+			expected: false,
+		},
+		{
+			name:     "given OK, expect no error",
+			given:    codes.OK,
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := ServerErrors(test.given)
+
+			if !cmp.Equal(actual, test.expected) {
+				t.Fatal(cmp.Diff(actual, test.expected))
+			}
+		})
+	}
+}
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenError     error
+		expectedReason string
+		expectedOK     bool
+	}{
+		{
+			name:           "given DeadlineExceeded, expect timeout",
+			givenError:     status.Error(codes.DeadlineExceeded, "fail"),
+			expectedReason: promred.ReasonTimeout,
+			expectedOK:     true,
+		},
+		{
+			name:           "given ResourceExhausted, expect throttled",
+			givenError:     status.Error(codes.ResourceExhausted, "fail"),
+			expectedReason: promred.ReasonThrottled,
+			expectedOK:     true,
+		},
+		{
+			name:           "given NotFound, expect not found",
+			givenError:     status.Error(codes.NotFound, "fail"),
+			expectedReason: promred.ReasonNotFound,
+			expectedOK:     true,
+		},
+		{
+			name:       "given Internal, expect it not to be recognised",
+			givenError: status.Error(codes.Internal, "fail"),
+		},
+		{
+			name:       "given error without a status, expect it not to be recognised",
+			givenError: errors.New("fail"),
+		},
+		{
+			name:       "given cancelled context, expect it to be left to promred.Reason",
+			givenError: context.Canceled,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualReason, actualOK := Reason(test.givenError)
+
+			if !cmp.Equal(actualReason, test.expectedReason) {
+				t.Fatal(cmp.Diff(actualReason, test.expectedReason))
+			}
+
+			if !cmp.Equal(actualOK, test.expectedOK) {
+				t.Fatal(cmp.Diff(actualOK, test.expectedOK))
+			}
+		})
+	}
+}
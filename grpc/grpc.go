@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jamieaitken/promred"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	_ grpc.UnaryServerInterceptor  = Interceptor{}.Unary
+	_ grpc.StreamServerInterceptor = Interceptor{}.Stream
+)
+
+type Interceptor struct {
+	recorder *promred.Recorder
+	isError  func(code codes.Code) bool
+}
+
+func New(opts ...Option) Interceptor {
+	o := newOptions(opts)
+
+	f := family
+	f.ResultLabelsOnDuration = o.statusOnDuration
+
+	return Interceptor{
+		recorder: promred.NewRecorder(f, o.recorder...),
+		isError:  o.isErrorStatus,
+	}
+}
+
+// Unary is a grpc.UnaryServerInterceptor recording each unary call by its full method and status code.
+func (i Interceptor) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	done := i.recorder.InFlight(info.FullMethod)
+	defer done()
+
+	start := time.Now()
+
+	res, err := handler(ctx, req)
+
+	i.record(ctx, time.Since(start), info.FullMethod, err)
+
+	return res, err
+}
+
+// Stream is a grpc.StreamServerInterceptor recording each streaming call by its full method and status code, once the
+// stream has finished.
+func (i Interceptor) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	done := i.recorder.InFlight(info.FullMethod)
+	defer done()
+
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	i.record(ss.Context(), time.Since(start), info.FullMethod, err)
+
+	return err
+}
+
+func (i Interceptor) record(ctx context.Context, d time.Duration, method string, err error) {
+	code := status.Code(err)
+	if !i.isError(code) {
+		err = nil
+	}
+
+	i.recorder.Record(ctx, d, err, method, code.String())
+}
@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestInterceptor_Unary(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenOptions           []Option
+		givenService           string
+		expectedCode           codes.Code
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given known service, expect operation count to be 1 and error count to be 0",
+			givenService:           "known",
+			expectedCode:           codes.OK,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:                   "given unknown service, expect operation count to be 1 and error count to be 1",
+			givenService:           "unknown",
+			expectedCode:           codes.NotFound,
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name:                   "given unknown service and server errors only, expect error count to be 0",
+			givenOptions:           []Option{WithErrorStatus(ServerErrors)},
+			givenService:           "unknown",
+			expectedCode:           codes.NotFound,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given unknown service, server errors only and a classifier counting every error, expect error count to be 0",
+			givenOptions: []Option{
+				WithErrorStatus(ServerErrors),
+				WithErrorClassifier(func(_ error) bool {
+					return true
+				}),
+			},
+			givenService:           "unknown",
+			expectedCode:           codes.NotFound,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given unknown service and a classifier counting no errors, expect error count to be 0",
+			givenOptions: []Option{
+				WithErrorClassifier(func(_ error) bool {
+					return false
+				}),
+			},
+			givenService:           "unknown",
+			expectedCode:           codes.NotFound,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			i := New(append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			client := newHealthClient(t, grpc.UnaryInterceptor(i.Unary))
+
+			_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: test.givenService})
+			if !cmp.Equal(status.Code(err), test.expectedCode) {
+				t.Fatal(cmp.Diff(status.Code(err), test.expectedCode))
+			}
+
+			labels := prometheus.Labels{"grpc_method": grpc_health_v1.Health_Check_FullMethodName, "grpc_code": test.expectedCode.String()}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "grpc_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "grpc_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestInterceptor_Stream(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenError             error
+		expectedCode           codes.Code
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given stream finishes, expect operation count to be 1 and error count to be 0",
+			expectedCode:           codes.OK,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:                   "given stream fails, expect operation count to be 1 and error count to be 1",
+			givenError:             status.Error(codes.Unavailable, "fail"),
+			expectedCode:           codes.Unavailable,
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name:                   "given client cancels the stream, expect operation count to be 1 and error count to be 0",
+			givenError:             status.Error(codes.Canceled, "fail"),
+			expectedCode:           codes.Canceled,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			i := New(WithRegisterer(reg))
+
+			info := &grpc.StreamServerInfo{FullMethod: grpc_health_v1.Health_Watch_FullMethodName, IsServerStream: true}
+
+			err := i.Stream(nil, mockServerStream{ctx: context.Background()}, info, func(_ any, _ grpc.ServerStream) error {
+				return test.givenError
+			})
+			if !cmp.Equal(status.Code(err), test.expectedCode) {
+				t.Fatal(cmp.Diff(status.Code(err), test.expectedCode))
+			}
+
+			labels := prometheus.Labels{"grpc_method": info.FullMethod, "grpc_code": test.expectedCode.String()}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "grpc_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "grpc_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func newHealthClient(t *testing.T, opts ...grpc.ServerOption) grpc_health_v1.HealthClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("known", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer(opts...)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (m mockServerStream) Context() context.Context {
+	return m.ctx
+}
@@ -0,0 +1,10 @@
+package grpc
+
+import "github.com/jamieaitken/promred"
+
+var family = promred.Family{
+	Name:         "grpc",
+	Noun:         "calls",
+	Labels:       []string{"grpc_method"},
+	ResultLabels: []string{"grpc_code"},
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/jamieaitken/promred"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc/codes"
+)
+
+// Option configures the metrics created by New.
+type Option func(*options)
+
+type options struct {
+	recorder         []promred.Option
+	isErrorStatus    func(code codes.Code) bool
+	statusOnDuration bool
+}
+
+// WithRegisterer registers the grpc metrics with reg rather than prometheus.DefaultRegisterer. See
+// promred.WithRegisterer for sharing reg between constructors.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return withRecorderOption(promred.WithRegisterer(reg))
+}
+
+// WithMeterProvider also emits the grpc metrics as OpenTelemetry instruments created from provider. See
+// promred.WithMeterProvider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return withRecorderOption(promred.WithMeterProvider(provider))
+}
+
+// WithNamespace prefixes the name of each grpc metric with namespace. See promred.WithNamespace.
+func WithNamespace(namespace string) Option {
+	return withRecorderOption(promred.WithNamespace(namespace))
+}
+
+// WithSubsystem prefixes the name of each grpc metric with subsystem, after any namespace. See promred.WithSubsystem.
+func WithSubsystem(subsystem string) Option {
+	return withRecorderOption(promred.WithSubsystem(subsystem))
+}
+
+// WithConstLabels adds labels with fixed values, such as service or env, to each grpc metric. See
+// promred.WithConstLabels.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return withRecorderOption(promred.WithConstLabels(labels))
+}
+
+// WithBuckets sets the upper bounds of the grpc_duration_seconds buckets, in seconds. See promred.WithBuckets.
+func WithBuckets(buckets []float64) Option {
+	return withRecorderOption(promred.WithBuckets(buckets))
+}
+
+// WithNativeHistogramBucketFactor exposes grpc_duration_seconds as a native histogram. See
+// promred.WithNativeHistogramBucketFactor.
+func WithNativeHistogramBucketFactor(factor float64) Option {
+	return withRecorderOption(promred.WithNativeHistogramBucketFactor(factor))
+}
+
+// WithTraceID sets how the trace ID attached to the grpc metrics as an exemplar is read from a context. See
+// promred.WithTraceID.
+func WithTraceID(traceID func(ctx context.Context) (string, bool)) Option {
+	return withRecorderOption(promred.WithTraceID(traceID))
+}
+
+// WithErrorClassifier sets which errors count towards grpc_error_total, defaulting to promred.IsError. It is applied
+// after WithErrorStatus, so is only called with errors whose status code WithErrorStatus counts as an error. See
+// promred.WithErrorClassifier.
+func WithErrorClassifier(isError func(err error) bool) Option {
+	return withRecorderOption(promred.WithErrorClassifier(isError))
+}
+
+// WithErrorReason adds a reason label to grpc_error_total, trying mappers in turn before Reason. See
+// promred.WithErrorReason.
+func WithErrorReason(mappers ...promred.ReasonMapper) Option {
+	return withRecorderOption(promred.WithErrorReason(append(mappers[:len(mappers):len(mappers)], Reason)...))
+}
+
+// WithInFlight adds a grpc_in_flight gauge of the calls currently being handled, labelled by grpc_method. See
+// promred.WithInFlight.
+func WithInFlight() Option {
+	return withRecorderOption(promred.WithInFlight())
+}
+
+// WithErrorStatus sets which status codes are errors, such as ServerErrors. It defaults to ClientAndServerErrors. It is
+// applied before WithErrorClassifier, so a code it does not count as an error never is, while one it does is only
+// counted if the classifier also counts its error.
+func WithErrorStatus(isError func(code codes.Code) bool) Option {
+	return func(o *options) {
+		o.isErrorStatus = isError
+	}
+}
+
+// WithStatusOnDuration also labels the duration histogram with the status code, so that the latency of successful calls
+// can be told apart from that of failed ones. It changes labels, so must be given to every constructor sharing a
+// registerer, or none.
+func WithStatusOnDuration() Option {
+	return func(o *options) {
+		o.statusOnDuration = true
+	}
+}
+
+func withRecorderOption(opt promred.Option) Option {
+	return func(o *options) {
+		o.recorder = append(o.recorder, opt)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		isErrorStatus: ClientAndServerErrors,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -1,53 +1,133 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/jamieaitken/promred"
 )
 
-var (
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
-)
+// StatusClientClosedRequest labels requests whose client went away before they were handled, whatever the handler
+// wrote.
+const StatusClientClosedRequest = 499
 
-func init() {
-	operationCount = withRate()
-	errorCount = withError()
-	duration = withDuration()
-}
+// errClientClosedRequest is the error of a request aborted by its client whose 499 WithErrorStatus counts as an error.
+// It wraps context.Canceled, so is still not counted as one unless WithErrorClassifier says so.
+var errClientClosedRequest = fmt.Errorf("%w: %w", &promred.StatusError{StatusCode: StatusClientClosedRequest}, context.Canceled)
 
 type Handler struct {
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	recorder     *promred.Recorder
+	requestSize  *promred.Histogram
+	responseSize *promred.Histogram
+	firstByte    *promred.Histogram
+	panicCount   *promred.Counter
+	onPanic      func(r *http.Request, recovered any, stack []byte)
+	pathLabelers []PathLabeler
+	isError      func(statusCode int) bool
+	statusLabel  func(statusCode int) string
 }
 
-func New() Handler {
+func New(opts ...Option) Handler {
+	o := newOptions(opts)
+
+	f, statusLabel := family, statusCodeLabel
+	if o.statusClass {
+		f.ResultLabels, statusLabel = []string{"status_class"}, statusClassLabel
+	}
+
+	f.ResultLabelsOnDuration = o.statusOnDuration
+
+	// The route a request matched is only known once it has been routed, so in-flight requests are labelled by method
+	// alone rather than all being labelled UnmatchedPath.
+	f.InFlightLabels = []string{"http_method"}
+
+	recorder := promred.NewRecorder(f, o.recorder...)
+
+	var panicCount *promred.Counter
+	if o.recoverPanic {
+		panicCount = withPanic(recorder)
+	}
+
 	return Handler{
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		recorder:     recorder,
+		requestSize:  withRequestSize(recorder, o.sizeBuckets),
+		responseSize: withResponseSize(recorder, o.sizeBuckets),
+		firstByte:    withTimeToFirstByte(recorder),
+		panicCount:   panicCount,
+		onPanic:      o.onPanic,
+		pathLabelers: o.pathLabelers,
+		isError:      o.isErrorStatus,
+		statusLabel:  statusLabel,
 	}
 }
 
 func (h Handler) HandleFor(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
-		timer := prometheus.NewTimer(h.duration.WithLabelValues(r.URL.Path, r.Method))
-		defer timer.ObserveDuration()
+	return h.Middleware(http.HandlerFunc(next)).ServeHTTP
+}
+
+// Middleware instruments every request served by next, such as a whole router or an http.FileServer.
+func (h Handler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done := h.recorder.InFlight(r.Method)
+		defer done()
 
+		start := time.Now()
+
+		r, body := withRequestBody(r)
 		rw := newResponseWriter(w)
 
-		next(rw, r)
+		// A panic left to the server still has its duration recorded, as a 500, though nothing else is known of it.
+		served := false
+		defer func() {
+			if !served {
+				h.recorder.RecordDuration(r.Context(), time.Since(start), pathFor(r, h.pathLabelers), r.Method,
+					h.statusLabel(http.StatusInternalServerError))
+			}
+		}()
+
+		panicked := h.serve(next, rw, r)
+		served = true
+
+		// Routers such as chi only know the route a request matched once they have handled it, so the path is labelled
+		// afterwards.
+		path := pathFor(r, h.pathLabelers)
 
-		statusCodeString := fmt.Sprint(rw.StatusCode)
+		statusCode, err := rw.StatusCode, h.statusError(rw.StatusCode)
+		if !panicked && errors.Is(r.Context().Err(), context.Canceled) {
+			statusCode, err = StatusClientClosedRequest, nil
+			if h.isError(StatusClientClosedRequest) {
+				err = errClientClosedRequest
+			}
+		}
 
-		h.operationCount.WithLabelValues(r.URL.Path, r.Method, statusCodeString).Inc()
+		h.recorder.Record(r.Context(), time.Since(start), err, path, r.Method, h.statusLabel(statusCode))
+		h.requestSize.Observe(r.Context(), float64(body.Size), path, r.Method)
+		h.responseSize.Observe(r.Context(), float64(rw.Size), path, r.Method)
+		h.firstByte.Observe(r.Context(), timeToFirstByte(start, rw).Seconds(), path, r.Method)
 
-		if rw.StatusCode >= 400 {
-			h.errorCount.WithLabelValues(r.URL.Path, r.Method, statusCodeString).Inc()
+		if panicked {
+			h.panicCount.Inc(r.Context(), path, r.Method)
 		}
+	})
+}
+
+// timeToFirstByte is the time until rw was first written to, or until now if it was not, as the server only responds
+// once the handler has returned.
+func timeToFirstByte(start time.Time, rw *responseWriter) time.Duration {
+	if rw.FirstByte.IsZero() {
+		return time.Since(start)
 	}
+
+	return rw.FirstByte.Sub(start)
+}
+
+func (h Handler) statusError(statusCode int) error {
+	if h.isError(statusCode) {
+		return &promred.StatusError{StatusCode: statusCode}
+	}
+
+	return nil
 }
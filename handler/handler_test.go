@@ -1,13 +1,25 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"github.com/jamieaitken/promred"
 	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
 func TestHandler_HandleFor(t *testing.T) {
@@ -59,8 +71,9 @@ func TestHandler_HandleFor(t *testing.T) {
 
 			statusCode := fmt.Sprint(rr.Code)
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*h.operationCount, test.givenHandler.GivenPath,
-				test.givenHandler.GivenMethod, statusCode)
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "handler_operation_total", prometheus.Labels{
+				"path": test.givenHandler.GivenPath, "http_method": test.givenHandler.GivenMethod, "status_code": statusCode,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -69,8 +82,9 @@ func TestHandler_HandleFor(t *testing.T) {
 				t.Fatalf(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*h.errorCount, test.givenHandler.GivenPath,
-				test.givenHandler.GivenMethod, statusCode)
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "handler_error_total", prometheus.Labels{
+				"path": test.givenHandler.GivenPath, "http_method": test.givenHandler.GivenMethod, "status_code": statusCode,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -82,6 +96,923 @@ func TestHandler_HandleFor(t *testing.T) {
 	}
 }
 
+func TestHandler_Middleware(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	tests := []struct {
+		name                   string
+		givenRouter            func(h Handler) http.Handler
+		givenPath              string
+		expectedLabels         prometheus.Labels
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name: "given wrapped ServeMux, expect the matched pattern to be labelled",
+			givenRouter: func(h Handler) http.Handler {
+				router := http.NewServeMux()
+				router.HandleFunc("GET /v1/users/{id}", ok)
+
+				return h.Middleware(router)
+			},
+			givenPath:              "/v1/users/123",
+			expectedLabels:         prometheus.Labels{"path": "/v1/users/{id}", "http_method": http.MethodGet, "status_code": "200"},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given wrapped ServeMux and no matching route, expect unmatched 404 to be an error",
+			givenRouter: func(h Handler) http.Handler {
+				router := http.NewServeMux()
+				router.HandleFunc("GET /v1/users/{id}", ok)
+
+				return h.Middleware(router)
+			},
+			givenPath:              "/v1/orders/123",
+			expectedLabels:         prometheus.Labels{"path": UnmatchedPath, "http_method": http.MethodGet, "status_code": "404"},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name: "given chi middleware, expect the matched pattern to be labelled",
+			givenRouter: func(h Handler) http.Handler {
+				router := chi.NewRouter()
+				router.Use(h.Middleware)
+				router.Get("/v1/users/{id}", ok)
+
+				return router
+			},
+			givenPath:              "/v1/users/123",
+			expectedLabels:         prometheus.Labels{"path": "/v1/users/{id}", "http_method": http.MethodGet, "status_code": "200"},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(WithRegisterer(reg), WithPathLabeler(ServeMuxPattern, ChiRoutePattern))
+
+			test.givenRouter(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.givenPath, nil))
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "handler_operation_total", test.expectedLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "handler_error_total", test.expectedLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNew_WithInFlight(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenRouter        func(h Handler, handle http.HandlerFunc) http.Handler
+		expectedDuringCall int
+		expectedAfterCall  int
+	}{
+		{
+			name: "given HandleFor, expect the gauge to be 1 while handling and 0 after",
+			givenRouter: func(h Handler, handle http.HandlerFunc) http.Handler {
+				return http.HandlerFunc(h.HandleFor(handle))
+			},
+			expectedDuringCall: 1,
+			expectedAfterCall:  0,
+		},
+		{
+			name: "given wrapped ServeMux, expect the gauge to be labelled by method and 1 while handling and 0 after",
+			givenRouter: func(h Handler, handle http.HandlerFunc) http.Handler {
+				router := http.NewServeMux()
+				router.HandleFunc("GET /v1/users/{id}", handle)
+
+				return h.Middleware(router)
+			},
+			expectedDuringCall: 1,
+			expectedAfterCall:  0,
+		},
+		{
+			name: "given chi middleware, expect the gauge to be labelled by method and 1 while handling and 0 after",
+			givenRouter: func(h Handler, handle http.HandlerFunc) http.Handler {
+				router := chi.NewRouter()
+				router.Use(h.Middleware)
+				router.Get("/v1/users/{id}", handle)
+
+				return router
+			},
+			expectedDuringCall: 1,
+			expectedAfterCall:  0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(WithRegisterer(reg), WithInFlight(), WithPathLabeler(ServeMuxPattern, ChiRoutePattern))
+
+			labels := prometheus.Labels{"http_method": http.MethodGet}
+
+			var (
+				actualDuringCall int
+				err              error
+			)
+
+			test.givenRouter(h, func(w http.ResponseWriter, _ *http.Request) {
+				actualDuringCall, err = testtool.GetGaugeValue(reg, "handler_in_flight", labels)
+
+				w.WriteHeader(http.StatusOK)
+			}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users/123", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualDuringCall, test.expectedDuringCall) {
+				t.Fatal(cmp.Diff(actualDuringCall, test.expectedDuringCall))
+			}
+
+			actualAfterCall, err := testtool.GetGaugeValue(reg, "handler_in_flight", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualAfterCall, test.expectedAfterCall) {
+				t.Fatal(cmp.Diff(actualAfterCall, test.expectedAfterCall))
+			}
+		})
+	}
+}
+
+func TestNew_WithMeterProvider(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenStatusCode        int
+		expectedOperationCount float64
+		expectedErrorCount     float64
+	}{
+		{
+			name:                   "given 500, expect operation count to be 1 and error count to be 1",
+			givenStatusCode:        http.StatusInternalServerError,
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+
+			h := New(WithRegisterer(nil), WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+			h.HandleFor(mockHandler{GivenStatusCode: test.givenStatusCode, GivenPath: "/v1/code", GivenMethod: http.MethodGet}.Get)(
+				httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+
+			attributes := []attribute.KeyValue{
+				attribute.String("path", "/v1/code"),
+				attribute.String("http_method", http.MethodGet),
+				attribute.String("status_code", fmt.Sprint(test.givenStatusCode)),
+			}
+
+			actualOperationCount, err := testtool.GetOTelSumValue(reader, "handler_operation_total", attributes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetOTelSumValue(reader, "handler_error_total", attributes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNew_WithPathLabeler(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	tests := []struct {
+		name          string
+		givenLabelers []PathLabeler
+		givenRouter   func(h Handler) http.Handler
+		expectedPath  string
+	}{
+		{
+			name:          "given ServeMux pattern, expect the pattern without its method",
+			givenLabelers: []PathLabeler{ServeMuxPattern},
+			givenRouter: func(h Handler) http.Handler {
+				router := http.NewServeMux()
+				router.HandleFunc("GET /v1/users/{id}", h.HandleFor(ok))
+
+				return router
+			},
+			expectedPath: "/v1/users/{id}",
+		},
+		{
+			name:          "given chi route pattern, expect the pattern",
+			givenLabelers: []PathLabeler{ChiRoutePattern},
+			givenRouter: func(h Handler) http.Handler {
+				router := chi.NewRouter()
+				router.Get("/v1/users/{id}", h.HandleFor(ok))
+
+				return router
+			},
+			expectedPath: "/v1/users/{id}",
+		},
+		{
+			name:          "given gorilla path template, expect the template",
+			givenLabelers: []PathLabeler{GorillaPathTemplate},
+			givenRouter: func(h Handler) http.Handler {
+				router := mux.NewRouter()
+				router.HandleFunc("/v1/users/{id}", h.HandleFor(ok))
+
+				return router
+			},
+			expectedPath: "/v1/users/{id}",
+		},
+		{
+			name:          "given no labeler matches, expect unmatched",
+			givenLabelers: []PathLabeler{ServeMuxPattern, ChiRoutePattern, GorillaPathTemplate},
+			givenRouter: func(h Handler) http.Handler {
+				return http.HandlerFunc(h.HandleFor(ok))
+			},
+			expectedPath: UnmatchedPath,
+		},
+		{
+			name:          "given fallback, expect the fallback when no labeler matches",
+			givenLabelers: []PathLabeler{ServeMuxPattern, Fallback("other")},
+			givenRouter: func(h Handler) http.Handler {
+				return http.HandlerFunc(h.HandleFor(ok))
+			},
+			expectedPath: "other",
+		},
+		{
+			name: "given no labelers, expect the URL path",
+			givenRouter: func(h Handler) http.Handler {
+				return http.HandlerFunc(h.HandleFor(ok))
+			},
+			expectedPath: "/v1/users/123",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			opts := []Option{WithRegisterer(reg)}
+
+			if test.givenLabelers != nil {
+				opts = append(opts, WithPathLabeler(test.givenLabelers...))
+			}
+
+			test.givenRouter(New(opts...)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users/123", nil))
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "handler_operation_total", prometheus.Labels{
+				"path": test.expectedPath, "http_method": http.MethodGet, "status_code": "200",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, 1) {
+				t.Fatal(cmp.Diff(actualOperationCount, 1))
+			}
+		})
+	}
+}
+
+func TestNew_RequestAndResponseSize(t *testing.T) {
+	tests := []struct {
+		name                 string
+		givenRequest         func() *http.Request
+		givenHandler         func(w http.ResponseWriter, r *http.Request)
+		expectedRequestSize  float64
+		expectedResponseSize float64
+	}{
+		{
+			name: "given content length, expect it as the request size without the body being read",
+			givenRequest: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/v1/code", strings.NewReader("12345"))
+			},
+			givenHandler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("123"))
+			},
+			expectedRequestSize:  5,
+			expectedResponseSize: 3,
+		},
+		{
+			name: "given unknown content length, expect the bytes read as the request size",
+			givenRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/v1/code", strings.NewReader("1234567"))
+				req.ContentLength = -1
+
+				return req
+			},
+			givenHandler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(w, r.Body)
+			},
+			expectedRequestSize:  7,
+			expectedResponseSize: 7,
+		},
+		{
+			name: "given no body, expect sizes of 0",
+			givenRequest: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/v1/code", nil)
+			},
+			givenHandler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectedRequestSize:  0,
+			expectedResponseSize: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(WithRegisterer(reg))
+
+			h.HandleFor(test.givenHandler)(httptest.NewRecorder(), test.givenRequest())
+
+			actualRequestSize, err := testtool.GetHistogram(reg, "handler_request_size_bytes")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualRequestSize.GetSampleSum(), test.expectedRequestSize) {
+				t.Fatal(cmp.Diff(actualRequestSize.GetSampleSum(), test.expectedRequestSize))
+			}
+
+			actualResponseSize, err := testtool.GetHistogram(reg, "handler_response_size_bytes")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualResponseSize.GetSampleSum(), test.expectedResponseSize) {
+				t.Fatal(cmp.Diff(actualResponseSize.GetSampleSum(), test.expectedResponseSize))
+			}
+		})
+	}
+}
+
+func TestNew_WithPanicRecovery(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenHandler           func(w http.ResponseWriter, r *http.Request)
+		expectedStatus         int
+		expectedRecovered      any
+		expectedOperationCount int
+		expectedErrorCount     int
+		expectedPanicCount     int
+	}{
+		{
+			name: "given panic before writing, expect a 500 to be written and counted",
+			givenHandler: func(_ http.ResponseWriter, _ *http.Request) {
+				panic("fail")
+			},
+			expectedStatus:         http.StatusInternalServerError,
+			expectedRecovered:      "fail",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+			expectedPanicCount:     1,
+		},
+		{
+			name: "given panic after writing, expect the written status to be kept but a 500 to be counted",
+			givenHandler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+
+				panic("fail")
+			},
+			expectedStatus:         http.StatusOK,
+			expectedRecovered:      "fail",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+			expectedPanicCount:     1,
+		},
+		{
+			name: "given no panic, expect no panic to be counted",
+			givenHandler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedStatus:         http.StatusOK,
+			expectedOperationCount: 0,
+			expectedErrorCount:     0,
+			expectedPanicCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			var actualRecovered any
+
+			h := New(WithRegisterer(reg), WithPanicRecovery(func(_ *http.Request, recovered any, stack []byte) {
+				actualRecovered = recovered
+
+				if len(stack) == 0 {
+					t.Fatal("expected a stack")
+				}
+			}))
+
+			rr := httptest.NewRecorder()
+
+			h.HandleFor(test.givenHandler)(rr, httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+
+			if !cmp.Equal(rr.Code, test.expectedStatus) {
+				t.Fatal(cmp.Diff(rr.Code, test.expectedStatus))
+			}
+
+			if !cmp.Equal(actualRecovered, test.expectedRecovered) {
+				t.Fatal(cmp.Diff(actualRecovered, test.expectedRecovered))
+			}
+
+			labels := prometheus.Labels{"path": "/v1/code", "http_method": http.MethodGet, "status_code": "500"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "handler_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "handler_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+
+			actualPanicCount, err := testtool.GetCounterValue(reg, "handler_panic_total", prometheus.Labels{
+				"path": "/v1/code", "http_method": http.MethodGet,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualPanicCount, test.expectedPanicCount) {
+				t.Fatal(cmp.Diff(actualPanicCount, test.expectedPanicCount))
+			}
+		})
+	}
+}
+
+func TestNew_PanicWithoutRecovery(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenOptions           []Option
+		expectedRecovered      any
+		expectedDurationCount  uint64
+		expectedOperationCount int
+	}{
+		{
+			name:                   "given panic, expect it to be left to the server with only its duration recorded",
+			expectedRecovered:      "fail",
+			expectedDurationCount:  1,
+			expectedOperationCount: 0,
+		},
+		{
+			name:                   "given panic and status on duration, expect only its duration to be recorded",
+			givenOptions:           []Option{WithStatusOnDuration()},
+			expectedRecovered:      "fail",
+			expectedDurationCount:  1,
+			expectedOperationCount: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			func() {
+				defer func() {
+					actualRecovered := recover()
+
+					if actualRecovered != test.expectedRecovered {
+						t.Fatalf("expected %v, got %v", test.expectedRecovered, actualRecovered)
+					}
+				}()
+
+				h.HandleFor(func(_ http.ResponseWriter, _ *http.Request) {
+					panic("fail")
+				})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+			}()
+
+			actualDuration, err := testtool.GetHistogram(reg, "handler_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualDuration.GetSampleCount(), test.expectedDurationCount) {
+				t.Fatal(cmp.Diff(actualDuration.GetSampleCount(), test.expectedDurationCount))
+			}
+
+			actualOperationCount, err := testutil.GatherAndCount(reg, "handler_operation_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+		})
+	}
+}
+
+func TestNew_WithPanicRecovery_ErrAbortHandler(t *testing.T) {
+	tests := []struct {
+		name              string
+		expectedRecovered any
+	}{
+		{
+			name:              "given http.ErrAbortHandler, expect it to be panicked again",
+			expectedRecovered: http.ErrAbortHandler,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := New(WithRegisterer(prometheus.NewRegistry()), WithPanicRecovery(nil))
+
+			defer func() {
+				actualRecovered := recover()
+
+				if actualRecovered != test.expectedRecovered {
+					t.Fatalf("expected %v, got %v", test.expectedRecovered, actualRecovered)
+				}
+			}()
+
+			h.HandleFor(func(_ http.ResponseWriter, _ *http.Request) {
+				panic(http.ErrAbortHandler)
+			})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+		})
+	}
+}
+
+func TestNew_ClientClosedRequest(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenOptions           []Option
+		givenCancel            bool
+		expectedStatusCode     string
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given client went away, expect 499 to be counted but not as an error",
+			givenCancel:            true,
+			expectedStatusCode:     "499",
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given client went away and a classifier counting everything, expect 499 to be counted as an error",
+			givenOptions: []Option{WithErrorClassifier(func(_ error) bool {
+				return true
+			})},
+			givenCancel:            true,
+			expectedStatusCode:     "499",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name: "given client went away, a classifier counting everything and server errors only, expect 499 not to be " +
+				"counted as an error",
+			givenOptions: []Option{
+				WithErrorStatus(ServerErrors),
+				WithErrorClassifier(func(_ error) bool {
+					return true
+				}),
+			},
+			givenCancel:            true,
+			expectedStatusCode:     "499",
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:                   "given client still there, expect the written status to be counted",
+			expectedStatusCode:     "200",
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			h.HandleFor(func(w http.ResponseWriter, _ *http.Request) {
+				if test.givenCancel {
+					cancel()
+				}
+
+				w.WriteHeader(http.StatusOK)
+			})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil).WithContext(ctx))
+
+			labels := prometheus.Labels{"path": "/v1/code", "http_method": http.MethodGet, "status_code": test.expectedStatusCode}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "handler_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "handler_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNew_TimeToFirstByte(t *testing.T) {
+	delay := 50 * time.Millisecond
+
+	tests := []struct {
+		name             string
+		givenHandler     func(w http.ResponseWriter, r *http.Request)
+		expectedAtLeast  time.Duration
+		expectedLessThan time.Duration
+	}{
+		{
+			name: "given write before a delay, expect the time to first byte to exclude the delay",
+			givenHandler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+
+				time.Sleep(delay)
+			},
+			expectedAtLeast:  0,
+			expectedLessThan: delay,
+		},
+		{
+			name: "given write after a delay, expect the time to first byte to include the delay",
+			givenHandler: func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(delay)
+
+				_, _ = w.Write([]byte("123"))
+			},
+			expectedAtLeast:  delay,
+			expectedLessThan: time.Minute,
+		},
+		{
+			name: "given no write, expect the time to first byte to be the whole call",
+			givenHandler: func(_ http.ResponseWriter, _ *http.Request) {
+				time.Sleep(delay)
+			},
+			expectedAtLeast:  delay,
+			expectedLessThan: time.Minute,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(WithRegisterer(reg))
+
+			h.HandleFor(test.givenHandler)(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+
+			actualHistogram, err := testtool.GetHistogram(reg, "handler_time_to_first_byte_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := time.Duration(actualHistogram.GetSampleSum() * float64(time.Second))
+
+			if actual < test.expectedAtLeast || actual >= test.expectedLessThan {
+				t.Fatalf("expected at least %s and less than %s, got %s", test.expectedAtLeast, test.expectedLessThan, actual)
+			}
+		})
+	}
+}
+
+func TestNew_WithErrorStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenOptions       []Option
+		givenStatusCode    int
+		expectedErrorCount int
+	}{
+		{
+			name:               "given 404 and no error status, expect it to be an error",
+			givenStatusCode:    http.StatusNotFound,
+			expectedErrorCount: 1,
+		},
+		{
+			name:               "given 404 and server errors only, expect it not to be an error",
+			givenOptions:       []Option{WithErrorStatus(ServerErrors)},
+			givenStatusCode:    http.StatusNotFound,
+			expectedErrorCount: 0,
+		},
+		{
+			name:               "given 502 and server errors only, expect it to be an error",
+			givenOptions:       []Option{WithErrorStatus(ServerErrors)},
+			givenStatusCode:    http.StatusBadGateway,
+			expectedErrorCount: 1,
+		},
+		{
+			name:               "given 500 and only 503 as an error, expect it not to be an error",
+			givenOptions:       []Option{WithErrorStatus(StatusCodes(http.StatusServiceUnavailable))},
+			givenStatusCode:    http.StatusInternalServerError,
+			expectedErrorCount: 0,
+		},
+		{
+			name: "given 404, server errors only and a classifier counting every error, expect it not to be an error",
+			givenOptions: []Option{
+				WithErrorStatus(ServerErrors),
+				WithErrorClassifier(func(_ error) bool {
+					return true
+				}),
+			},
+			givenStatusCode:    http.StatusNotFound,
+			expectedErrorCount: 0,
+		},
+		{
+			name: "given 502, server errors only and a classifier rejecting 502, expect it not to be an error",
+			givenOptions: []Option{
+				WithErrorStatus(ServerErrors),
+				WithErrorClassifier(func(err error) bool {
+					var statusErr *promred.StatusError
+
+					return !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway
+				}),
+			},
+			givenStatusCode:    http.StatusBadGateway,
+			expectedErrorCount: 0,
+		},
+		{
+			name: "given 503, server errors only and a classifier rejecting 502, expect it to be an error",
+			givenOptions: []Option{
+				WithErrorStatus(ServerErrors),
+				WithErrorClassifier(func(err error) bool {
+					var statusErr *promred.StatusError
+
+					return !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway
+				}),
+			},
+			givenStatusCode:    http.StatusServiceUnavailable,
+			expectedErrorCount: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			h.HandleFor(mockHandler{GivenStatusCode: test.givenStatusCode, GivenPath: "/v1/code", GivenMethod: http.MethodGet}.Get)(
+				httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "handler_error_total", prometheus.Labels{
+				"path": "/v1/code", "http_method": http.MethodGet, "status_code": fmt.Sprint(test.givenStatusCode),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNew_WithStatusClass(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenStatusCodes       []int
+		expectedStatusClass    string
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given 404 and 409, expect both to be counted as 4xx",
+			givenStatusCodes:       []int{http.StatusNotFound, http.StatusConflict},
+			expectedStatusClass:    "4xx",
+			expectedOperationCount: 2,
+			expectedErrorCount:     2,
+		},
+		{
+			name:                   "given 200 and 204, expect both to be counted as 2xx",
+			givenStatusCodes:       []int{http.StatusOK, http.StatusNoContent},
+			expectedStatusClass:    "2xx",
+			expectedOperationCount: 2,
+			expectedErrorCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(WithRegisterer(reg), WithStatusClass())
+
+			for _, statusCode := range test.givenStatusCodes {
+				h.HandleFor(mockHandler{GivenStatusCode: statusCode, GivenPath: "/v1/code", GivenMethod: http.MethodGet}.Get)(
+					httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+			}
+
+			labels := prometheus.Labels{"path": "/v1/code", "http_method": http.MethodGet, "status_class": test.expectedStatusClass}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "handler_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "handler_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNew_WithStatusOnDuration(t *testing.T) {
+	tests := []struct {
+		name                string
+		givenOptions        []Option
+		givenStatusCodes    []int
+		expectedSeriesCount int
+	}{
+		{
+			name:                "given 200 and 500, expect a duration series for each",
+			givenOptions:        []Option{WithStatusOnDuration()},
+			givenStatusCodes:    []int{http.StatusOK, http.StatusInternalServerError},
+			expectedSeriesCount: 2,
+		},
+		{
+			name:                "given 500 and 503 with status class, expect a single duration series",
+			givenOptions:        []Option{WithStatusOnDuration(), WithStatusClass()},
+			givenStatusCodes:    []int{http.StatusInternalServerError, http.StatusServiceUnavailable},
+			expectedSeriesCount: 1,
+		},
+		{
+			name:                "given 200 and 500 without status on duration, expect a single duration series",
+			givenStatusCodes:    []int{http.StatusOK, http.StatusInternalServerError},
+			expectedSeriesCount: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := New(append([]Option{WithRegisterer(reg)}, test.givenOptions...)...)
+
+			for _, statusCode := range test.givenStatusCodes {
+				h.HandleFor(mockHandler{GivenStatusCode: statusCode, GivenPath: "/v1/code", GivenMethod: http.MethodGet}.Get)(
+					httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil))
+			}
+
+			actualSeriesCount, err := testutil.GatherAndCount(reg, "handler_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualSeriesCount, test.expectedSeriesCount) {
+				t.Fatal(cmp.Diff(actualSeriesCount, test.expectedSeriesCount))
+			}
+		})
+	}
+}
+
 type mockHandler struct {
 	GivenStatusCode int
 	GivenPath       string
@@ -1,41 +1,33 @@
 package handler
 
-import "github.com/prometheus/client_golang/prometheus"
-
-var (
-	requestLabels  = []string{"path", "http_method"}
-	responseLabels = []string{"path", "http_method", "status_code"}
+import (
+	"github.com/jamieaitken/promred"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func withRate() *prometheus.CounterVec {
-	r := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "handler_operation_total",
-		Help: "The number of requests",
-	}, responseLabels)
-
-	prometheus.MustRegister(r)
-
-	return r
+var family = promred.Family{
+	Name:         "handler",
+	Noun:         "requests",
+	Labels:       []string{"path", "http_method"},
+	ResultLabels: []string{"status_code"},
 }
 
-func withError() *prometheus.CounterVec {
-	r := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "handler_error_total",
-		Help: "The number of those requests that have failed",
-	}, responseLabels)
+var defaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 8)
 
-	prometheus.MustRegister(r)
-
-	return r
+func withRequestSize(recorder *promred.Recorder, buckets []float64) *promred.Histogram {
+	return recorder.Histogram("request_size_bytes", "The size of the bodies of those requests", buckets, family.Labels...)
 }
 
-func withDuration() *prometheus.HistogramVec {
-	d := prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name: "handler_duration_seconds",
-		Help: "The amount of time those requests take",
-	}, requestLabels)
+func withResponseSize(recorder *promred.Recorder, buckets []float64) *promred.Histogram {
+	return recorder.Histogram("response_size_bytes", "The size of the bodies of the responses to those requests", buckets,
+		family.Labels...)
+}
 
-	prometheus.MustRegister(d)
+func withPanic(recorder *promred.Recorder) *promred.Counter {
+	return recorder.Counter("panic_total", "The number of those requests that have panicked", family.Labels...)
+}
 
-	return d
+func withTimeToFirstByte(recorder *promred.Recorder) *promred.Histogram {
+	return recorder.Histogram("time_to_first_byte_seconds", "The amount of time those requests take to start responding", nil,
+		family.Labels...)
 }
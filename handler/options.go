@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jamieaitken/promred"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Option configures the metrics created by New.
+type Option func(*options)
+
+type options struct {
+	recorder         []promred.Option
+	pathLabelers     []PathLabeler
+	sizeBuckets      []float64
+	recoverPanic     bool
+	onPanic          func(r *http.Request, recovered any, stack []byte)
+	isErrorStatus    func(statusCode int) bool
+	statusClass      bool
+	statusOnDuration bool
+}
+
+// WithRegisterer registers the handler metrics with reg rather than prometheus.DefaultRegisterer. See
+// promred.WithRegisterer for sharing reg between constructors.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return withRecorderOption(promred.WithRegisterer(reg))
+}
+
+// WithMeterProvider also emits the handler metrics as OpenTelemetry instruments created from provider. See
+// promred.WithMeterProvider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return withRecorderOption(promred.WithMeterProvider(provider))
+}
+
+// WithNamespace prefixes the name of each handler metric with namespace. See promred.WithNamespace.
+func WithNamespace(namespace string) Option {
+	return withRecorderOption(promred.WithNamespace(namespace))
+}
+
+// WithSubsystem prefixes the name of each handler metric with subsystem, after any namespace. See
+// promred.WithSubsystem.
+func WithSubsystem(subsystem string) Option {
+	return withRecorderOption(promred.WithSubsystem(subsystem))
+}
+
+// WithConstLabels adds labels with fixed values, such as service or env, to each handler metric. See
+// promred.WithConstLabels.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return withRecorderOption(promred.WithConstLabels(labels))
+}
+
+// WithBuckets sets the upper bounds of the buckets of the duration and time to first byte histograms, in seconds. It
+// defaults to prometheus.DefBuckets. The size histograms are set by WithSizeBuckets instead. See promred.WithBuckets.
+func WithBuckets(buckets []float64) Option {
+	return withRecorderOption(promred.WithBuckets(buckets))
+}
+
+// WithNativeHistogramBucketFactor exposes every histogram, of durations, time to first byte and sizes alike, as a
+// native histogram, where factor is the maximum growth from one bucket to the next, e.g. 1.1. Classic buckets are only
+// kept alongside it if WithBuckets is given, other than those of the size histograms, which are always kept. See
+// promred.WithNativeHistogramBucketFactor.
+func WithNativeHistogramBucketFactor(factor float64) Option {
+	return withRecorderOption(promred.WithNativeHistogramBucketFactor(factor))
+}
+
+// WithTraceID sets how the trace ID attached to the handler metrics as an exemplar is read from a context. See
+// promred.WithTraceID.
+func WithTraceID(traceID func(ctx context.Context) (string, bool)) Option {
+	return withRecorderOption(promred.WithTraceID(traceID))
+}
+
+// WithErrorClassifier sets which errors count towards handler_error_total, defaulting to promred.IsError. It is
+// applied after WithErrorStatus, so is only called with the *promred.StatusError of a response whose status
+// WithErrorStatus counts as an error, including the 499 of a request aborted by its client. See
+// promred.WithErrorClassifier.
+func WithErrorClassifier(isError func(err error) bool) Option {
+	return withRecorderOption(promred.WithErrorClassifier(isError))
+}
+
+// WithErrorReason adds a reason label to handler_error_total, trying mappers in turn before promred.Reason. See
+// promred.WithErrorReason.
+func WithErrorReason(mappers ...promred.ReasonMapper) Option {
+	return withRecorderOption(promred.WithErrorReason(mappers...))
+}
+
+// WithInFlight adds a handler_in_flight gauge of the requests currently being handled, labelled by http_method alone,
+// as the route a request matched is not known until it has been handled. See promred.WithInFlight.
+func WithInFlight() Option {
+	return withRecorderOption(promred.WithInFlight())
+}
+
+// WithPathLabeler labels the path of each request with the first of labelers able to, rather than its URL path, so
+// that routes such as /v1/users/{id} do not create a series per ID. Requests none of them can label are labelled
+// UnmatchedPath.
+func WithPathLabeler(labelers ...PathLabeler) Option {
+	return func(o *options) {
+		o.pathLabelers = labelers
+	}
+}
+
+// WithSizeBuckets sets the upper bounds of the request and response size histogram buckets, in bytes. It defaults to
+// 100B through to 10GB in powers of ten.
+func WithSizeBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.sizeBuckets = buckets
+	}
+}
+
+// WithPanicRecovery recovers panics from the handlers being instrumented, counting them in handler_panic_total and the
+// request as a 500, which is written if the handler had not yet written a response. onPanic, which may be nil, is
+// called with the recovered value and the stack of the panic. Without it, panics are left to the server and only the
+// duration of their request is recorded.
+func WithPanicRecovery(onPanic func(r *http.Request, recovered any, stack []byte)) Option {
+	return func(o *options) {
+		o.recoverPanic = true
+		o.onPanic = onPanic
+	}
+}
+
+// WithErrorStatus sets which response statuses are errors, such as ServerErrors or StatusCodes(500, 503). It defaults
+// to ClientAndServerErrors. It is applied before WithErrorClassifier, so a status it does not count as an error never
+// is, while one it does is only counted if the classifier also counts its *promred.StatusError.
+func WithErrorStatus(isError func(statusCode int) bool) Option {
+	return func(o *options) {
+		o.isErrorStatus = isError
+	}
+}
+
+// WithStatusClass labels requests by their status class, such as 2xx or 5xx, in a status_class label rather than by
+// their status code, to cut the number of series. It changes labels, so must be given to every constructor sharing a
+// registerer, or none.
+func WithStatusClass() Option {
+	return func(o *options) {
+		o.statusClass = true
+	}
+}
+
+// WithStatusOnDuration also labels the duration histogram with the status code, or status class if WithStatusClass is
+// given, so that the latency of successful requests can be told apart from that of failed ones. It changes labels, so
+// must be given to every constructor sharing a registerer, or none.
+func WithStatusOnDuration() Option {
+	return func(o *options) {
+		o.statusOnDuration = true
+	}
+}
+
+func withRecorderOption(opt promred.Option) Option {
+	return func(o *options) {
+		o.recorder = append(o.recorder, opt)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		pathLabelers:  []PathLabeler{URLPath},
+		sizeBuckets:   defaultSizeBuckets,
+		isErrorStatus: ClientAndServerErrors,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
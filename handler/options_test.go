@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestOptions(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reader := sdkmetric.NewManualReader()
+
+	h := New(
+		WithRegisterer(reg),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithNamespace("team"),
+		WithSubsystem("service"),
+		WithConstLabels(prometheus.Labels{"env": "test"}),
+		WithBuckets([]float64{1}),
+		WithNativeHistogramBucketFactor(1.1),
+		WithTraceID(func(_ context.Context) (string, bool) {
+			return "abc", true
+		}),
+		WithErrorClassifier(func(_ error) bool {
+			return true
+		}),
+		WithErrorReason(),
+		WithInFlight(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h.HandleFor(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/code", nil).WithContext(ctx))
+
+	// A request aborted by its client is only counted as an error through the classifier, and labelled canceled by
+	// promred.Reason.
+	expectedExposition := `
+# HELP team_service_handler_error_total The number of those requests that have failed
+# TYPE team_service_handler_error_total counter
+team_service_handler_error_total{env="test",http_method="GET",path="/v1/code",reason="canceled",status_code="499"} 1
+# HELP team_service_handler_in_flight The number of those requests in flight
+# TYPE team_service_handler_in_flight gauge
+team_service_handler_in_flight{env="test",http_method="GET"} 0
+# HELP team_service_handler_operation_total The number of requests
+# TYPE team_service_handler_operation_total counter
+team_service_handler_operation_total{env="test",http_method="GET",path="/v1/code",status_code="499"} 1
+`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expectedExposition), "team_service_handler_operation_total",
+		"team_service_handler_error_total", "team_service_handler_in_flight")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	histogram, err := testtool.GetHistogram(reg, "team_service_handler_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(len(histogram.GetBucket()), 1) {
+		t.Fatal(cmp.Diff(len(histogram.GetBucket()), 1))
+	}
+
+	if histogram.Schema == nil {
+		t.Fatal("expected a native histogram")
+	}
+
+	actualTraceIDs, err := testtool.GetExemplarTraceIDs(reg, "team_service_handler_operation_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualTraceIDs, []string{"abc"}) {
+		t.Fatal(cmp.Diff(actualTraceIDs, []string{"abc"}))
+	}
+
+	actualOTelDuration, err := testtool.GetOTelHistogram(reader, "team_service_handler_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualOTelDuration.Count, uint64(1)) {
+		t.Fatal(cmp.Diff(actualOTelDuration.Count, uint64(1)))
+	}
+
+	actualDefaultMetricCount, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "team_service_handler_operation_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualDefaultMetricCount, 0) {
+		t.Fatal(cmp.Diff(actualDefaultMetricCount, 0))
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// serve calls next, recovering any panic when WithPanicRecovery is given and reporting whether it did.
+func (h Handler) serve(next http.Handler, rw *responseWriter, r *http.Request) (panicked bool) {
+	if h.panicCount == nil {
+		next.ServeHTTP(delegate(rw), r)
+
+		return false
+	}
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		// http.ErrAbortHandler is how a handler deliberately aborts a response, so is left for the server to handle.
+		if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+			panic(recovered)
+		}
+
+		panicked = true
+
+		if h.onPanic != nil {
+			h.onPanic(r, recovered, debug.Stack())
+		}
+
+		if !rw.WroteHeader {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+
+		rw.StatusCode = http.StatusInternalServerError
+	}()
+
+	next.ServeHTTP(delegate(rw), r)
+
+	return false
+}
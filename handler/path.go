@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/mux"
+)
+
+// UnmatchedPath labels requests which none of the PathLabelers given to WithPathLabeler could label.
+const UnmatchedPath = "unmatched"
+
+// PathLabeler returns the value of the path label for r, or false if it cannot label r.
+type PathLabeler func(r *http.Request) (string, bool)
+
+// ServeMuxPattern labels requests by the http.ServeMux pattern they matched, without its method, e.g. /v1/users/{id}.
+func ServeMuxPattern(r *http.Request) (string, bool) {
+	if r.Pattern == "" {
+		return "", false
+	}
+
+	_, pattern, ok := strings.Cut(r.Pattern, " ")
+	if !ok {
+		return r.Pattern, true
+	}
+
+	return strings.TrimLeft(pattern, " \t"), true
+}
+
+// ChiRoutePattern labels requests by the chi route pattern they matched, e.g. /v1/users/{id}.
+func ChiRoutePattern(r *http.Request) (string, bool) {
+	routeContext := chi.RouteContext(r.Context())
+	if routeContext == nil {
+		return "", false
+	}
+
+	pattern := routeContext.RoutePattern()
+
+	return pattern, pattern != ""
+}
+
+// GorillaPathTemplate labels requests by the path template of the gorilla/mux route they matched, e.g. /v1/users/{id}.
+func GorillaPathTemplate(r *http.Request) (string, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+
+	return template, true
+}
+
+// URLPath labels requests by their URL path, which is how they are labelled unless WithPathLabeler is given.
+func URLPath(r *http.Request) (string, bool) {
+	return r.URL.Path, true
+}
+
+// Fallback labels every request with path. Given last to WithPathLabeler, it replaces UnmatchedPath.
+func Fallback(path string) PathLabeler {
+	return func(_ *http.Request) (string, bool) {
+		return path, true
+	}
+}
+
+func pathFor(r *http.Request, labelers []PathLabeler) string {
+	for _, labeler := range labelers {
+		path, ok := labeler(r)
+		if ok {
+			return path
+		}
+	}
+
+	return UnmatchedPath
+}
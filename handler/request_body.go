@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+)
+
+type requestBody struct {
+	io.ReadCloser
+	Size int64
+}
+
+func (b *requestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.Size += int64(n)
+
+	return n, err
+}
+
+// withRequestBody counts the bytes read from the body of r when its length is not known up front, such as when it is
+// chunked.
+func withRequestBody(r *http.Request) (*http.Request, *requestBody) {
+	if r.ContentLength >= 0 || r.Body == nil {
+		return r, &requestBody{ReadCloser: r.Body, Size: max(r.ContentLength, 0)}
+	}
+
+	body := &requestBody{ReadCloser: r.Body}
+
+	r = r.WithContext(r.Context())
+	r.Body = body
+
+	return r, body
+}
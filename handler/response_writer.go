@@ -1,20 +1,240 @@
 package handler
 
-import "net/http"
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
 
 type responseWriter struct {
 	http.ResponseWriter
-	StatusCode int
+	StatusCode  int
+	Size        int64
+	WroteHeader bool
+	FirstByte   time.Time
 }
 
 func newResponseWriter(writer http.ResponseWriter) *responseWriter {
 	return &responseWriter{
-		writer,
-		http.StatusOK,
+		ResponseWriter: writer,
+		StatusCode:     http.StatusOK,
 	}
 }
 
 func (r *responseWriter) WriteHeader(statusCode int) {
 	r.StatusCode = statusCode
+	r.writing()
 	r.ResponseWriter.WriteHeader(statusCode)
 }
+
+func (r *responseWriter) Write(b []byte) (int, error) {
+	r.writing()
+
+	n, err := r.ResponseWriter.Write(b)
+	r.Size += int64(n)
+
+	return n, err
+}
+
+func (r *responseWriter) writing() {
+	if r.WroteHeader {
+		return
+	}
+
+	r.WroteHeader = true
+	r.FirstByte = time.Now()
+}
+
+// Unwrap lets http.ResponseController reach the optional interfaces of the underlying writer.
+func (r *responseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+type flusher struct{ *responseWriter }
+
+func (f flusher) Flush() {
+	f.writing()
+	f.ResponseWriter.(http.Flusher).Flush()
+}
+
+type hijacker struct{ *responseWriter }
+
+// Hijack records a connection taken over by the handler, such as for a WebSocket, as switching protocols unless a
+// status had already been written.
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := h.ResponseWriter.(http.Hijacker).Hijack()
+	if err != nil {
+		return conn, rw, err
+	}
+
+	if !h.WroteHeader {
+		h.StatusCode = http.StatusSwitchingProtocols
+	}
+
+	h.writing()
+
+	return conn, rw, nil
+}
+
+type pusher struct{ *responseWriter }
+
+func (p pusher) Push(target string, opts *http.PushOptions) error {
+	return p.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+type readerFrom struct{ *responseWriter }
+
+func (rf readerFrom) ReadFrom(src io.Reader) (int64, error) {
+	rf.writing()
+
+	n, err := rf.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+	rf.Size += n
+
+	return n, err
+}
+
+const (
+	isFlusher = 1 << iota
+	isHijacker
+	isPusher
+	isReaderFrom
+)
+
+// delegators returns a writer exposing exactly the optional interfaces of the underlying writer, indexed by which of
+// them it implements.
+var delegators = [16]func(rw *responseWriter) http.ResponseWriter{
+	func(rw *responseWriter) http.ResponseWriter {
+		return rw
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+		}{rw, flusher{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			hijacker
+		}{rw, hijacker{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+		}{rw, flusher{rw}, hijacker{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			pusher
+		}{rw, pusher{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+		}{rw, flusher{rw}, pusher{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+		}{rw, hijacker{rw}, pusher{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+		}{rw, flusher{rw}, hijacker{rw}, pusher{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			readerFrom
+		}{rw, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+		}{rw, flusher{rw}, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+		}{rw, hijacker{rw}, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+		}{rw, flusher{rw}, hijacker{rw}, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			pusher
+			readerFrom
+		}{rw, pusher{rw}, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+			readerFrom
+		}{rw, flusher{rw}, pusher{rw}, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+			readerFrom
+		}{rw, hijacker{rw}, pusher{rw}, readerFrom{rw}}
+	},
+	func(rw *responseWriter) http.ResponseWriter {
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+			readerFrom
+		}{rw, flusher{rw}, hijacker{rw}, pusher{rw}, readerFrom{rw}}
+	},
+}
+
+func delegate(rw *responseWriter) http.ResponseWriter {
+	i := 0
+
+	if _, ok := rw.ResponseWriter.(http.Flusher); ok {
+		i |= isFlusher
+	}
+
+	if _, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		i |= isHijacker
+	}
+
+	if _, ok := rw.ResponseWriter.(http.Pusher); ok {
+		i |= isPusher
+	}
+
+	if _, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		i |= isReaderFrom
+	}
+
+	return delegators[i](rw)
+}
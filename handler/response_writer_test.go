@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDelegate(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	tests := []struct {
+		name               string
+		givenWriter        http.ResponseWriter
+		expectedFlusher    bool
+		expectedHijacker   bool
+		expectedPusher     bool
+		expectedReaderFrom bool
+	}{
+		{
+			name:        "given writer without optional interfaces, expect none",
+			givenWriter: struct{ http.ResponseWriter }{recorder},
+		},
+		{
+			name:            "given flusher, expect only flusher",
+			givenWriter:     recorder,
+			expectedFlusher: true,
+		},
+		{
+			name: "given hijacker and reader from, expect only hijacker and reader from",
+			givenWriter: struct {
+				http.ResponseWriter
+				mockHijacker
+				mockReaderFrom
+			}{recorder, mockHijacker{}, mockReaderFrom{}},
+			expectedHijacker:   true,
+			expectedReaderFrom: true,
+		},
+		{
+			name: "given every optional interface, expect every optional interface",
+			givenWriter: struct {
+				*httptest.ResponseRecorder
+				mockHijacker
+				mockPusher
+				mockReaderFrom
+			}{recorder, mockHijacker{}, mockPusher{}, mockReaderFrom{}},
+			expectedFlusher:    true,
+			expectedHijacker:   true,
+			expectedPusher:     true,
+			expectedReaderFrom: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rw := newResponseWriter(test.givenWriter)
+
+			actual := delegate(rw)
+
+			_, actualFlusher := actual.(http.Flusher)
+			if !cmp.Equal(actualFlusher, test.expectedFlusher) {
+				t.Fatal(cmp.Diff(actualFlusher, test.expectedFlusher))
+			}
+
+			_, actualHijacker := actual.(http.Hijacker)
+			if !cmp.Equal(actualHijacker, test.expectedHijacker) {
+				t.Fatal(cmp.Diff(actualHijacker, test.expectedHijacker))
+			}
+
+			_, actualPusher := actual.(http.Pusher)
+			if !cmp.Equal(actualPusher, test.expectedPusher) {
+				t.Fatal(cmp.Diff(actualPusher, test.expectedPusher))
+			}
+
+			_, actualReaderFrom := actual.(io.ReaderFrom)
+			if !cmp.Equal(actualReaderFrom, test.expectedReaderFrom) {
+				t.Fatal(cmp.Diff(actualReaderFrom, test.expectedReaderFrom))
+			}
+
+			actual.WriteHeader(http.StatusAccepted)
+
+			if !cmp.Equal(rw.StatusCode, http.StatusAccepted) {
+				t.Fatal(cmp.Diff(rw.StatusCode, http.StatusAccepted))
+			}
+		})
+	}
+}
+
+func TestResponseWriter_Unwrap(t *testing.T) {
+	tests := []struct {
+		name                  string
+		givenDeadline         time.Time
+		expectedWriteDeadline time.Time
+	}{
+		{
+			name:                  "given http.ResponseController, expect it to reach the underlying writer",
+			givenDeadline:         time.Unix(1, 0),
+			expectedWriteDeadline: time.Unix(1, 0),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer := &mockDeadlineWriter{ResponseWriter: httptest.NewRecorder()}
+
+			h := New()
+
+			h.HandleFor(func(w http.ResponseWriter, _ *http.Request) {
+				err := http.NewResponseController(w).SetWriteDeadline(test.givenDeadline)
+				if err != nil {
+					t.Fatal(err)
+				}
+			})(writer, httptest.NewRequest(http.MethodGet, "/v1/stream", nil))
+
+			if !cmp.Equal(writer.writeDeadline, test.expectedWriteDeadline) {
+				t.Fatal(cmp.Diff(writer.writeDeadline, test.expectedWriteDeadline))
+			}
+		})
+	}
+}
+
+func TestHijacker_Hijack(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenWriteHeader   bool
+		expectedStatusCode int
+	}{
+		{
+			name:               "given nothing written, expect switching protocols",
+			expectedStatusCode: http.StatusSwitchingProtocols,
+		},
+		{
+			name:               "given a status already written, expect it to be kept",
+			givenWriteHeader:   true,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rw := newResponseWriter(struct {
+				http.ResponseWriter
+				mockHijacker
+			}{httptest.NewRecorder(), mockHijacker{}})
+
+			actual := delegate(rw)
+
+			if test.givenWriteHeader {
+				actual.WriteHeader(http.StatusBadRequest)
+			}
+
+			_, _, err := actual.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(rw.StatusCode, test.expectedStatusCode) {
+				t.Fatal(cmp.Diff(rw.StatusCode, test.expectedStatusCode))
+			}
+
+			if rw.FirstByte.IsZero() {
+				t.Fatal("expected the first byte to be recorded")
+			}
+		})
+	}
+}
+
+type mockDeadlineWriter struct {
+	http.ResponseWriter
+	writeDeadline time.Time
+}
+
+func (m *mockDeadlineWriter) SetWriteDeadline(deadline time.Time) error {
+	m.writeDeadline = deadline
+
+	return nil
+}
+
+type mockHijacker struct{}
+
+func (mockHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+type mockPusher struct{}
+
+func (mockPusher) Push(_ string, _ *http.PushOptions) error {
+	return nil
+}
+
+type mockReaderFrom struct{}
+
+func (mockReaderFrom) ReadFrom(_ io.Reader) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ClientAndServerErrors counts 4xx and 5xx statuses as errors, which is what WithErrorStatus defaults to.
+func ClientAndServerErrors(statusCode int) bool {
+	return statusCode >= 400
+}
+
+// ServerErrors counts only 5xx statuses as errors, such as for SLOs which do not hold clients' mistakes against a
+// service.
+func ServerErrors(statusCode int) bool {
+	return statusCode >= 500
+}
+
+// StatusCodes counts only statusCodes as errors.
+func StatusCodes(statusCodes ...int) func(statusCode int) bool {
+	return func(statusCode int) bool {
+		return slices.Contains(statusCodes, statusCode)
+	}
+}
+
+func statusCodeLabel(statusCode int) string {
+	return fmt.Sprint(statusCode)
+}
+
+func statusClassLabel(statusCode int) string {
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
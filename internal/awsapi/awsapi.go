@@ -0,0 +1,41 @@
+// Package awsapi provides the middleware shared by the sns and sqs packages for instrumenting AWS SDK clients.
+package awsapi
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/jamieaitken/promred"
+)
+
+// serviceMetadataID is the ID of the middleware by which every AWS SDK client records its service ID.
+const serviceMetadataID = "RegisterServiceMetadata"
+
+// NewAPIOption returns an option which instruments every operation of clients for the service identified by
+// serviceID, using recorder. The middleware is added under id, so options for different services can share a stack,
+// and operations of any other service, such as those of an aws.Config shared between clients, are left untouched.
+func NewAPIOption(id, serviceID string, recorder *promred.Recorder) func(stack *middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Insert(middleware.InitializeMiddlewareFunc(id, func(ctx context.Context,
+			in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if awsmiddleware.GetServiceID(ctx) != serviceID {
+				return next.HandleInitialize(ctx, in)
+			}
+
+			var (
+				out      middleware.InitializeOutput
+				metadata middleware.Metadata
+			)
+
+			err := recorder.ObserveContext(ctx, stack.ID(), func() error {
+				var err error
+				out, metadata, err = next.HandleInitialize(ctx, in)
+
+				return err
+			})
+
+			return out, metadata, err
+		}), serviceMetadataID, middleware.After)
+	}
+}
@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/jamieaitken/promred"
+	"github.com/segmentio/kafka-go"
+)
+
+// ContextReader matches the ReadMessage and Close methods of *kafka.Reader, reading the invoker from the context as set
+// by promred.WithInvoker. It has none of its other methods, such as FetchMessage and CommitMessages. Close has no
+// context, so is always labelled with the default invoker.
+type ContextReader struct {
+	provider readerProvider
+	recorder *promred.Recorder
+}
+
+func NewContextReader(reader readerProvider, opts ...Option) ContextReader {
+	o := newOptions(opts)
+
+	return ContextReader{
+		provider: reader,
+		recorder: promred.NewRecorder(family, o.recorder...),
+	}
+}
+
+func (r ContextReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	var msg kafka.Message
+
+	err := r.recorder.ObserveContext(ctx, "ReadMessage", func() error {
+		var err error
+		msg, err = r.provider.ReadMessage(ctx)
+
+		return err
+	})
+
+	return msg, err
+}
+
+func (r ContextReader) Close() error {
+	return r.recorder.ObserveContext(context.Background(), "ReaderClose", r.provider.Close)
+}
+
+// ContextWriter matches the WriteMessages and Close methods of *kafka.Writer, reading the invoker from the context as
+// set by promred.WithInvoker. It has none of its other methods, such as Stats. Close has no context, so is always
+// labelled with the default invoker.
+type ContextWriter struct {
+	provider writerProvider
+	recorder *promred.Recorder
+}
+
+func NewContextWriter(writer writerProvider, opts ...Option) ContextWriter {
+	o := newOptions(opts)
+
+	return ContextWriter{
+		provider: writer,
+		recorder: promred.NewRecorder(family, o.recorder...),
+	}
+}
+
+func (w ContextWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	return w.recorder.ObserveContext(ctx, "WriteMessages", func() error {
+		return w.provider.WriteMessages(ctx, msgs...)
+	})
+}
+
+func (w ContextWriter) Close() error {
+	return w.recorder.ObserveContext(context.Background(), "WriterClose", w.provider.Close)
+}
+
+// ContextHeartbeater matches the Heartbeat method of *kafka.Client, reading the invoker from the context as set by
+// promred.WithInvoker.
+type ContextHeartbeater struct {
+	provider heartbeatProvider
+	recorder *promred.Recorder
+}
+
+func NewContextHeartbeater(client heartbeatProvider, opts ...Option) ContextHeartbeater {
+	o := newOptions(opts)
+
+	return ContextHeartbeater{
+		provider: client,
+		recorder: promred.NewRecorder(family, o.recorder...),
+	}
+}
+
+func (h ContextHeartbeater) Heartbeat(ctx context.Context, req *kafka.HeartbeatRequest) (*kafka.HeartbeatResponse, error) {
+	var (
+		res *kafka.HeartbeatResponse
+		err error
+	)
+
+	_ = h.recorder.ObserveContext(ctx, "Heartbeat", func() error {
+		res, err = h.provider.Heartbeat(ctx, req)
+
+		return heartbeatError(res, err)
+	})
+
+	return res, err
+}
@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestContextReader(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenReader            mockReader
+		givenCall              func(r ContextReader) error
+		givenOperation         string
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name: "given failed read, expect operation count to be 1 and error count to be 1",
+			givenReader: mockReader{
+				GivenReadMessageError: errors.New("fail"),
+			},
+			givenCall: func(r ContextReader) error {
+				_, err := r.ReadMessage(promred.WithInvoker(context.Background(), "test"))
+
+				return err
+			},
+			givenOperation:         "ReadMessage",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name:        "given successful close, expect operation count to be 1 and error count to be 0",
+			givenReader: mockReader{},
+			givenCall: func(r ContextReader) error {
+				return r.Close()
+			},
+			givenOperation:         "ReaderClose",
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			_ = test.givenCall(NewContextReader(test.givenReader, WithRegisterer(reg), WithDefaultInvoker("test")))
+
+			assertCounts(t, reg, test.givenOperation, test.expectedOperationCount, test.expectedErrorCount)
+		})
+	}
+}
+
+func TestContextWriter(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenWriter            mockWriter
+		givenCall              func(w ContextWriter) error
+		givenOperation         string
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:        "given successful write, expect operation count to be 1 and error count to be 0",
+			givenWriter: mockWriter{},
+			givenCall: func(w ContextWriter) error {
+				return w.WriteMessages(promred.WithInvoker(context.Background(), "test"), kafka.Message{})
+			},
+			givenOperation:         "WriteMessages",
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given failed close, expect operation count to be 1 and error count to be 1",
+			givenWriter: mockWriter{
+				GivenCloseError: errors.New("fail"),
+			},
+			givenCall: func(w ContextWriter) error {
+				return w.Close()
+			},
+			givenOperation:         "WriterClose",
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			_ = test.givenCall(NewContextWriter(test.givenWriter, WithRegisterer(reg), WithDefaultInvoker("test")))
+
+			assertCounts(t, reg, test.givenOperation, test.expectedOperationCount, test.expectedErrorCount)
+		})
+	}
+}
+
+func TestContextHeartbeater_Heartbeat(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenHeartbeater       mockHeartbeater
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name: "given successful heartbeat, expect operation count to be 1 and error count to be 0",
+			givenHeartbeater: mockHeartbeater{
+				GivenHeartbeatRes: &kafka.HeartbeatResponse{},
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given error in heartbeat response, expect operation count to be 1 and error count to be 1",
+			givenHeartbeater: mockHeartbeater{
+				GivenHeartbeatRes: &kafka.HeartbeatResponse{
+					Error: errors.New("fail"),
+				},
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := NewContextHeartbeater(test.givenHeartbeater, WithRegisterer(reg))
+
+			_, _ = h.Heartbeat(promred.WithInvoker(context.Background(), "test"), &kafka.HeartbeatRequest{})
+
+			assertCounts(t, reg, "Heartbeat", test.expectedOperationCount, test.expectedErrorCount)
+		})
+	}
+}
+
+func assertCounts(t *testing.T, reg prometheus.Gatherer, operation string, expectedOperationCount, expectedErrorCount int) {
+	t.Helper()
+
+	labels := prometheus.Labels{"invoker": "test", "operation": operation}
+
+	actualOperationCount, err := testtool.GetCounterValue(reg, "kafka_operation_total", labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualOperationCount, expectedOperationCount) {
+		t.Fatal(cmp.Diff(actualOperationCount, expectedOperationCount))
+	}
+
+	actualErrorCount, err := testtool.GetCounterValue(reg, "kafka_error_total", labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualErrorCount, expectedErrorCount) {
+		t.Fatal(cmp.Diff(actualErrorCount, expectedErrorCount))
+	}
+}
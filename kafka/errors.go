@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"errors"
+	"io"
+
+	"github.com/jamieaitken/promred"
+	"github.com/segmentio/kafka-go"
+)
+
+// IsError is the default error classifier, which extends promred.IsError so that reading from a closed reader,
+// io.EOF, is not a failure.
+func IsError(err error) bool {
+	return !errors.Is(err, io.EOF) && promred.IsError(err)
+}
+
+// Reason is the built-in promred.ReasonMapper for kafka, which maps the kafka.Error codes for timeouts and unknown
+// topics or partitions.
+func Reason(err error) (string, bool) {
+	var kafkaErr kafka.Error
+	if !errors.As(err, &kafkaErr) {
+		return "", false
+	}
+
+	switch {
+	case kafkaErr.Timeout():
+		return promred.ReasonTimeout, true
+	case kafkaErr == kafka.UnknownTopicOrPartition:
+		return promred.ReasonNotFound, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestIsError(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenError    error
+		expectedError bool
+	}{
+		{
+			name:          "given error, expect error",
+			givenError:    errors.New("fail"),
+			expectedError: true,
+		},
+		{
+			name:          "given closed reader, expect no error",
+			givenError:    io.EOF,
+			expectedError: false,
+		},
+		{
+			name:          "given cancelled context, expect no error",
+			givenError:    context.Canceled,
+			expectedError: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualError := IsError(test.givenError)
+
+			if !cmp.Equal(actualError, test.expectedError) {
+				t.Fatal(cmp.Diff(actualError, test.expectedError))
+			}
+		})
+	}
+}
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenError     error
+		expectedReason string
+		expectedOk     bool
+	}{
+		{
+			name:           "given request timed out, expect timeout",
+			givenError:     fmt.Errorf("write: %w", kafka.RequestTimedOut),
+			expectedReason: promred.ReasonTimeout,
+			expectedOk:     true,
+		},
+		{
+			name:           "given unknown topic or partition, expect not_found",
+			givenError:     kafka.UnknownTopicOrPartition,
+			expectedReason: promred.ReasonNotFound,
+			expectedOk:     true,
+		},
+		{
+			name:       "given other kafka error, expect no reason",
+			givenError: kafka.LeaderNotAvailable,
+		},
+		{
+			name:       "given unrecognised error, expect no reason",
+			givenError: errors.New("fail"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualReason, actualOk := Reason(test.givenError)
+
+			if !cmp.Equal(actualReason, test.expectedReason) {
+				t.Fatal(cmp.Diff(actualReason, test.expectedReason))
+			}
+
+			if !cmp.Equal(actualOk, test.expectedOk) {
+				t.Fatal(cmp.Diff(actualOk, test.expectedOk))
+			}
+		})
+	}
+}
@@ -3,16 +3,10 @@ package kafka
 import (
 	"context"
 
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/jamieaitken/promred"
 	"github.com/segmentio/kafka-go"
 )
 
-var (
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
-)
-
 type readerProvider interface {
 	ReadMessage(ctx context.Context) (kafka.Message, error)
 	Close() error
@@ -27,130 +21,98 @@ type heartbeatProvider interface {
 	Heartbeat(ctx context.Context, req *kafka.HeartbeatRequest) (*kafka.HeartbeatResponse, error)
 }
 
-func init() {
-	operationCount = withRate()
-	errorCount = withError()
-	duration = withDuration()
-}
-
 type Reader struct {
-	provider       readerProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	provider readerProvider
+	recorder *promred.Recorder
 }
 
-func NewReader(reader readerProvider) Reader {
+func NewReader(reader readerProvider, opts ...Option) Reader {
+	o := newOptions(opts)
+
 	return Reader{
-		provider:       reader,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		provider: reader,
+		recorder: promred.NewRecorder(family, o.recorder...),
 	}
 }
 
 func (r Reader) ReadMessage(ctx context.Context, invoker string) (kafka.Message, error) {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "ReadMessage"))
-	defer timer.ObserveDuration()
+	var msg kafka.Message
 
-	r.operationCount.WithLabelValues(invoker, "ReadMessage").Inc()
+	err := r.recorder.Observe(ctx, invoker, "ReadMessage", func() error {
+		var err error
+		msg, err = r.provider.ReadMessage(ctx)
 
-	msg, err := r.provider.ReadMessage(ctx)
-	if err != nil {
-		r.errorCount.WithLabelValues(invoker, "ReadMessage").Inc()
-	}
+		return err
+	})
 
 	return msg, err
 }
 
 func (r Reader) Close(invoker string) error {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "ReaderClose"))
-	defer timer.ObserveDuration()
-
-	r.operationCount.WithLabelValues(invoker, "ReaderClose").Inc()
-
-	err := r.provider.Close()
-	if err != nil {
-		r.errorCount.WithLabelValues(invoker, "ReaderClose").Inc()
-	}
-
-	return err
+	return r.recorder.Observe(context.Background(), invoker, "ReaderClose", r.provider.Close)
 }
 
 type Writer struct {
-	provider       writerProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	provider writerProvider
+	recorder *promred.Recorder
 }
 
-func NewWriter(writer writerProvider) Writer {
+func NewWriter(writer writerProvider, opts ...Option) Writer {
+	o := newOptions(opts)
+
 	return Writer{
-		provider:       writer,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		provider: writer,
+		recorder: promred.NewRecorder(family, o.recorder...),
 	}
 }
 
 func (w Writer) WriteMessages(ctx context.Context, msgs []kafka.Message, invoker string) error {
-	timer := prometheus.NewTimer(w.duration.WithLabelValues(invoker, "WriteMessages"))
-	defer timer.ObserveDuration()
-
-	w.operationCount.WithLabelValues(invoker, "WriteMessages").Inc()
-
-	err := w.provider.WriteMessages(ctx, msgs...)
-	if err != nil {
-		w.errorCount.WithLabelValues(invoker, "WriteMessages").Inc()
-	}
-
-	return err
+	return w.recorder.Observe(ctx, invoker, "WriteMessages", func() error {
+		return w.provider.WriteMessages(ctx, msgs...)
+	})
 }
 
 func (w Writer) Close(invoker string) error {
-	timer := prometheus.NewTimer(w.duration.WithLabelValues(invoker, "WriterClose"))
-	defer timer.ObserveDuration()
-
-	w.operationCount.WithLabelValues(invoker, "WriterClose").Inc()
-
-	err := w.provider.Close()
-	if err != nil {
-		w.errorCount.WithLabelValues(invoker, "WriterClose").Inc()
-	}
-
-	return err
+	return w.recorder.Observe(context.Background(), invoker, "WriterClose", w.provider.Close)
 }
 
 type Heartbeater struct {
-	provider       heartbeatProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	provider heartbeatProvider
+	recorder *promred.Recorder
 }
 
-func NewHeartbeater(client heartbeatProvider) Heartbeater {
+func NewHeartbeater(client heartbeatProvider, opts ...Option) Heartbeater {
+	o := newOptions(opts)
+
 	return Heartbeater{
-		provider:       client,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		provider: client,
+		recorder: promred.NewRecorder(family, o.recorder...),
 	}
 }
 
 func (h Heartbeater) Heartbeat(ctx context.Context, req *kafka.HeartbeatRequest, invoker string) (*kafka.HeartbeatResponse, error) {
-	timer := prometheus.NewTimer(h.duration.WithLabelValues(invoker, "Heartbeat"))
-	defer timer.ObserveDuration()
+	var (
+		res *kafka.HeartbeatResponse
+		err error
+	)
 
-	h.operationCount.WithLabelValues(invoker, "Heartbeat").Inc()
+	_ = h.recorder.Observe(ctx, invoker, "Heartbeat", func() error {
+		res, err = h.provider.Heartbeat(ctx, req)
 
-	res, err := h.provider.Heartbeat(ctx, req)
+		return heartbeatError(res, err)
+	})
+
+	return res, err
+}
+
+func heartbeatError(res *kafka.HeartbeatResponse, err error) error {
 	if err != nil {
-		h.errorCount.WithLabelValues(invoker, "Heartbeat").Inc()
+		return err
 	}
 
-	if res.Error != nil {
-		h.errorCount.WithLabelValues(invoker, "Heartbeat").Inc()
+	if res != nil {
+		return res.Error
 	}
 
-	return res, err
+	return nil
 }
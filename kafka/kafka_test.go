@@ -3,10 +3,13 @@ package kafka
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -38,7 +41,9 @@ func TestReader_ReadMessage(t *testing.T) {
 
 			_, _ = r.ReadMessage(context.Background(), "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "ReadMessage")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReadMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -47,7 +52,9 @@ func TestReader_ReadMessage(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "ReadMessage")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReadMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -87,7 +94,9 @@ func TestReader_Close(t *testing.T) {
 
 			_ = r.Close("test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "ReaderClose")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReaderClose",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -96,7 +105,9 @@ func TestReader_Close(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "ReaderClose")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReaderClose",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -136,7 +147,9 @@ func TestWriter_WriteMessages(t *testing.T) {
 
 			_ = r.WriteMessages(context.Background(), nil, "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "WriteMessages")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "WriteMessages",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -145,7 +158,9 @@ func TestWriter_WriteMessages(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "WriteMessages")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "WriteMessages",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -185,7 +200,9 @@ func TestWriter_Close(t *testing.T) {
 
 			_ = r.Close("test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "WriterClose")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "WriterClose",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -194,7 +211,9 @@ func TestWriter_Close(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "WriterClose")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "WriterClose",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -240,6 +259,25 @@ func TestHeartbeater_Heartbeat(t *testing.T) {
 			expectedErrorCount:     2,
 			expectedOperationCount: 3,
 		},
+		{
+			name: "given failed heartbeat with error in heartbeat response, expect operation count to be 4 and error count to be 3",
+			givenHeartbeater: mockHeartbeater{
+				GivenHeartbeatRes: &kafka.HeartbeatResponse{
+					Error: errors.New("fail"),
+				},
+				GivenHeartbeatError: errors.New("fail"),
+			},
+			expectedErrorCount:     3,
+			expectedOperationCount: 4,
+		},
+		{
+			name: "given failed heartbeat with nil response, expect operation count to be 5 and error count to be 4",
+			givenHeartbeater: mockHeartbeater{
+				GivenHeartbeatError: errors.New("fail"),
+			},
+			expectedErrorCount:     4,
+			expectedOperationCount: 5,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -247,7 +285,9 @@ func TestHeartbeater_Heartbeat(t *testing.T) {
 
 			_, _ = r.Heartbeat(context.Background(), &kafka.HeartbeatRequest{}, "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "Heartbeat")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "Heartbeat",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -256,7 +296,9 @@ func TestHeartbeater_Heartbeat(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "Heartbeat")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "kafka_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "Heartbeat",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -268,6 +310,87 @@ func TestHeartbeater_Heartbeat(t *testing.T) {
 	}
 }
 
+func TestNewReader_Options(t *testing.T) {
+	tests := []struct {
+		name                       string
+		expectedExposition         string
+		expectedBucketCount        int
+		expectedNativeHistogram    bool
+		expectedTraceIDs           []string
+		expectedDefaultMetricCount int
+	}{
+		{
+			name: "given every recorder option, expect each to be forwarded to the recorder",
+			expectedExposition: `
+# HELP team_service_kafka_operation_total The number of operations
+# TYPE team_service_kafka_operation_total counter
+team_service_kafka_operation_total{env="test",invoker="test",operation="ReadMessage"} 1
+`,
+			expectedBucketCount:        1,
+			expectedNativeHistogram:    true,
+			expectedTraceIDs:           []string{"abc"},
+			expectedDefaultMetricCount: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			opts := []Option{
+				WithRegisterer(reg),
+				WithNamespace("team"),
+				WithSubsystem("service"),
+				WithConstLabels(prometheus.Labels{"env": "test"}),
+				WithBuckets([]float64{1}),
+				WithNativeHistogramBucketFactor(1.1),
+				WithTraceID(func(_ context.Context) (string, bool) {
+					return "abc", true
+				}),
+			}
+
+			r := NewReader(mockReader{}, opts...)
+
+			_, _ = r.ReadMessage(context.Background(), "test")
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.expectedExposition), "team_service_kafka_operation_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			histogram, err := testtool.GetHistogram(reg, "team_service_kafka_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(len(histogram.GetBucket()), test.expectedBucketCount) {
+				t.Fatal(cmp.Diff(len(histogram.GetBucket()), test.expectedBucketCount))
+			}
+
+			actualNativeHistogram := histogram.Schema != nil
+			if !cmp.Equal(actualNativeHistogram, test.expectedNativeHistogram) {
+				t.Fatal(cmp.Diff(actualNativeHistogram, test.expectedNativeHistogram))
+			}
+
+			actualTraceIDs, err := testtool.GetExemplarTraceIDs(reg, "team_service_kafka_operation_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualTraceIDs, test.expectedTraceIDs) {
+				t.Fatal(cmp.Diff(actualTraceIDs, test.expectedTraceIDs))
+			}
+
+			actualDefaultMetricCount, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "team_service_kafka_operation_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualDefaultMetricCount, test.expectedDefaultMetricCount) {
+				t.Fatal(cmp.Diff(actualDefaultMetricCount, test.expectedDefaultMetricCount))
+			}
+		})
+	}
+}
+
 type mockReader struct {
 	GivenReadMessageMsg   kafka.Message
 	GivenReadMessageError error
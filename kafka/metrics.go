@@ -1,38 +1,8 @@
 package kafka
 
-import "github.com/prometheus/client_golang/prometheus"
+import "github.com/jamieaitken/promred"
 
-var labels = []string{"invoker", "operation"}
-
-func withRate() *prometheus.CounterVec {
-	r := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "kafka_operation_total",
-		Help: "The number of operations",
-	}, labels)
-
-	prometheus.MustRegister(r)
-
-	return r
-}
-
-func withError() *prometheus.CounterVec {
-	r := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "kafka_error_total",
-		Help: "The number of those operations that have failed",
-	}, labels)
-
-	prometheus.MustRegister(r)
-
-	return r
-}
-
-func withDuration() *prometheus.HistogramVec {
-	d := prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name: "kafka_duration_seconds",
-		Help: "The amount of time those operations take",
-	}, labels)
-
-	prometheus.MustRegister(d)
-
-	return d
+var family = promred.Family{
+	Name: "kafka",
+	Noun: "operations",
 }
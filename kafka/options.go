@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/jamieaitken/promred"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Option configures the metrics created by each constructor.
+type Option func(*options)
+
+type options struct {
+	recorder []promred.Option
+}
+
+// WithRegisterer registers the kafka metrics with reg rather than prometheus.DefaultRegisterer. See
+// promred.WithRegisterer for sharing reg between constructors.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return withRecorderOption(promred.WithRegisterer(reg))
+}
+
+// WithMeterProvider also emits the kafka metrics as OpenTelemetry instruments created from provider. See
+// promred.WithMeterProvider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return withRecorderOption(promred.WithMeterProvider(provider))
+}
+
+// WithNamespace prefixes the name of each kafka metric with namespace. See promred.WithNamespace.
+func WithNamespace(namespace string) Option {
+	return withRecorderOption(promred.WithNamespace(namespace))
+}
+
+// WithSubsystem prefixes the name of each kafka metric with subsystem, after any namespace. See promred.WithSubsystem.
+func WithSubsystem(subsystem string) Option {
+	return withRecorderOption(promred.WithSubsystem(subsystem))
+}
+
+// WithConstLabels adds labels with fixed values, such as service or env, to each kafka metric. See
+// promred.WithConstLabels.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return withRecorderOption(promred.WithConstLabels(labels))
+}
+
+// WithBuckets sets the upper bounds of the kafka_duration_seconds buckets, in seconds. See promred.WithBuckets.
+func WithBuckets(buckets []float64) Option {
+	return withRecorderOption(promred.WithBuckets(buckets))
+}
+
+// WithNativeHistogramBucketFactor exposes kafka_duration_seconds as a native histogram. See
+// promred.WithNativeHistogramBucketFactor.
+func WithNativeHistogramBucketFactor(factor float64) Option {
+	return withRecorderOption(promred.WithNativeHistogramBucketFactor(factor))
+}
+
+// WithTraceID sets how the trace ID attached to the kafka metrics as an exemplar is read from a context. See
+// promred.WithTraceID.
+func WithTraceID(traceID func(ctx context.Context) (string, bool)) Option {
+	return withRecorderOption(promred.WithTraceID(traceID))
+}
+
+// WithDefaultInvoker labels calls whose context carries no invoker, such as those made through NewContextReader, with
+// invoker rather than unknown. See promred.WithDefaultInvoker.
+func WithDefaultInvoker(invoker string) Option {
+	return withRecorderOption(promred.WithDefaultInvoker(invoker))
+}
+
+// WithErrorClassifier sets which errors count towards kafka_error_total, defaulting to IsError, under which reading
+// from a closed reader is not an error. See promred.WithErrorClassifier.
+func WithErrorClassifier(isError func(err error) bool) Option {
+	return withRecorderOption(promred.WithErrorClassifier(isError))
+}
+
+// WithErrorReason adds a reason label to kafka_error_total, trying mappers in turn before Reason. See
+// promred.WithErrorReason.
+func WithErrorReason(mappers ...promred.ReasonMapper) Option {
+	return withRecorderOption(promred.WithErrorReason(append(mappers[:len(mappers):len(mappers)], Reason)...))
+}
+
+// WithInFlight adds a kafka_in_flight gauge of the calls currently being made, labelled by invoker and operation. See
+// promred.WithInFlight.
+func WithInFlight() Option {
+	return withRecorderOption(promred.WithInFlight())
+}
+
+func withRecorderOption(opt promred.Option) Option {
+	return func(o *options) {
+		o.recorder = append(o.recorder, opt)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		recorder: []promred.Option{promred.WithErrorClassifier(IsError)},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestOptions(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reader := sdkmetric.NewManualReader()
+
+	r := NewContextReader(mockReader{GivenReadMessageError: io.EOF},
+		WithRegisterer(reg),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithNamespace("team"),
+		WithSubsystem("service"),
+		WithConstLabels(prometheus.Labels{"env": "test"}),
+		WithBuckets([]float64{1}),
+		WithNativeHistogramBucketFactor(1.1),
+		WithTraceID(func(_ context.Context) (string, bool) {
+			return "abc", true
+		}),
+		WithDefaultInvoker("test"),
+		WithErrorClassifier(func(_ error) bool {
+			return true
+		}),
+		WithErrorReason(func(_ error) (string, bool) {
+			return "closed", true
+		}),
+		WithInFlight(),
+	)
+
+	_, _ = r.ReadMessage(context.Background())
+
+	// Reading from a closed reader is only counted as an error through the classifier.
+	expectedExposition := `
+# HELP team_service_kafka_error_total The number of those operations that have failed
+# TYPE team_service_kafka_error_total counter
+team_service_kafka_error_total{env="test",invoker="test",operation="ReadMessage",reason="closed"} 1
+# HELP team_service_kafka_in_flight The number of those operations in flight
+# TYPE team_service_kafka_in_flight gauge
+team_service_kafka_in_flight{env="test",invoker="test",operation="ReadMessage"} 0
+# HELP team_service_kafka_operation_total The number of operations
+# TYPE team_service_kafka_operation_total counter
+team_service_kafka_operation_total{env="test",invoker="test",operation="ReadMessage"} 1
+`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expectedExposition), "team_service_kafka_operation_total",
+		"team_service_kafka_error_total", "team_service_kafka_in_flight")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	histogram, err := testtool.GetHistogram(reg, "team_service_kafka_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(len(histogram.GetBucket()), 1) {
+		t.Fatal(cmp.Diff(len(histogram.GetBucket()), 1))
+	}
+
+	if histogram.Schema == nil {
+		t.Fatal("expected a native histogram")
+	}
+
+	actualTraceIDs, err := testtool.GetExemplarTraceIDs(reg, "team_service_kafka_operation_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualTraceIDs, []string{"abc"}) {
+		t.Fatal(cmp.Diff(actualTraceIDs, []string{"abc"}))
+	}
+
+	actualOTelDuration, err := testtool.GetOTelHistogram(reader, "team_service_kafka_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualOTelDuration.Count, uint64(1)) {
+		t.Fatal(cmp.Diff(actualOTelDuration.Count, uint64(1)))
+	}
+
+	actualDefaultMetricCount, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "team_service_kafka_operation_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualDefaultMetricCount, 0) {
+		t.Fatal(cmp.Diff(actualDefaultMetricCount, 0))
+	}
+}
@@ -0,0 +1,129 @@
+package promred
+
+import (
+	"context"
+	"fmt"
+)
+
+type counter interface {
+	inc(ctx context.Context, lvs []string)
+}
+
+type histogram interface {
+	observe(ctx context.Context, value float64, lvs []string)
+}
+
+type gauge interface {
+	add(ctx context.Context, delta float64, lvs []string)
+}
+
+type counters []counter
+
+func (cs counters) inc(ctx context.Context, lvs []string) {
+	for _, c := range cs {
+		c.inc(ctx, lvs)
+	}
+}
+
+type histograms []histogram
+
+func (hs histograms) observe(ctx context.Context, value float64, lvs []string) {
+	for _, h := range hs {
+		h.observe(ctx, value, lvs)
+	}
+}
+
+type gauges []gauge
+
+func (gs gauges) add(ctx context.Context, delta float64, lvs []string) {
+	for _, g := range gs {
+		g.add(ctx, delta, lvs)
+	}
+}
+
+type desc struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+}
+
+func newCounter(o options, d desc) counter {
+	var cs counters
+
+	if o.registerer != nil {
+		cs = append(cs, newPrometheusCounter(o, d))
+	}
+
+	if o.meterProvider != nil {
+		cs = append(cs, newOTelCounter(o, d))
+	}
+
+	return cs
+}
+
+func newHistogram(o options, d desc) histogram {
+	var hs histograms
+
+	if o.registerer != nil {
+		hs = append(hs, newPrometheusHistogram(o, d))
+	}
+
+	if o.meterProvider != nil {
+		hs = append(hs, newOTelHistogram(o, d))
+	}
+
+	return hs
+}
+
+func newGauge(o options, d desc) gauge {
+	var gs gauges
+
+	if o.registerer != nil {
+		gs = append(gs, newPrometheusGauge(o, d))
+	}
+
+	if o.meterProvider != nil {
+		gs = append(gs, newOTelGauge(o, d))
+	}
+
+	return gs
+}
+
+func withRate(f Family, o options) counter {
+	return newCounter(o, desc{
+		name:   fmt.Sprintf("%s_operation_total", f.Name),
+		help:   fmt.Sprintf("The number of %s", f.Noun),
+		labels: f.resultLabels(),
+	})
+}
+
+func withError(f Family, o options) counter {
+	labels := f.resultLabels()
+	if o.reason {
+		labels = append(labels, "reason")
+	}
+
+	return newCounter(o, desc{
+		name:   fmt.Sprintf("%s_error_total", f.Name),
+		help:   fmt.Sprintf("The number of those %s that have failed", f.Noun),
+		labels: labels,
+	})
+}
+
+func withDuration(f Family, o options) histogram {
+	return newHistogram(o, desc{
+		name:    fmt.Sprintf("%s_duration_seconds", f.Name),
+		help:    fmt.Sprintf("The amount of time those %s take", f.Noun),
+		labels:  f.durationLabels(),
+		buckets: o.buckets,
+	})
+}
+
+func withInFlight(f Family, o options) gauge {
+	return newGauge(o, desc{
+		name:   fmt.Sprintf("%s_in_flight", f.Name),
+		help:   fmt.Sprintf("The number of those %s in flight", f.Noun),
+		labels: f.inFlightLabels(),
+	})
+}
@@ -0,0 +1,151 @@
+package promred
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures the metrics created by NewRecorder.
+type Option func(*options)
+
+type options struct {
+	registerer                  prometheus.Registerer
+	meterProvider               metric.MeterProvider
+	namespace                   string
+	subsystem                   string
+	constLabels                 prometheus.Labels
+	buckets                     []float64
+	nativeHistogramBucketFactor float64
+	traceID                     func(ctx context.Context) (string, bool)
+	defaultInvoker              string
+	isError                     func(err error) bool
+	reason                      bool
+	reasonMappers               []ReasonMapper
+	inFlight                    bool
+}
+
+// WithRegisterer registers the collectors with reg rather than prometheus.DefaultRegisterer. A nil reg creates no
+// Prometheus metrics at all, for when they are only wanted through WithMeterProvider. Recorders sharing reg share their
+// metrics, so must agree on options which change labels, such as WithErrorReason, and keep the buckets of whichever
+// registered them first.
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = reg
+	}
+}
+
+// WithMeterProvider also creates each metric as an OpenTelemetry instrument from provider, with the same name,
+// attributes and buckets. Exemplars are left to the provider, so WithTraceID and WithNativeHistogramBucketFactor
+// only apply to Prometheus.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = provider
+	}
+}
+
+// WithNamespace prefixes each metric name with namespace.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithSubsystem prefixes each metric name with subsystem, after any namespace.
+func WithSubsystem(subsystem string) Option {
+	return func(o *options) {
+		o.subsystem = subsystem
+	}
+}
+
+// WithConstLabels adds labels with fixed values, such as service or env, to each metric.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(o *options) {
+		o.constLabels = labels
+	}
+}
+
+// WithBuckets sets the upper bounds of the duration histogram buckets, in seconds. It defaults to prometheus.DefBuckets.
+// A histogram already registered by another Recorder keeps its own buckets.
+func WithBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// WithNativeHistogramBucketFactor exposes the duration histogram as a native histogram, where factor is the maximum
+// growth from one bucket to the next, e.g. 1.1. Classic buckets are only kept alongside it if WithBuckets is given.
+func WithNativeHistogramBucketFactor(factor float64) Option {
+	return func(o *options) {
+		o.nativeHistogramBucketFactor = factor
+	}
+}
+
+// WithTraceID sets how the trace ID attached to each observation as an exemplar is read from a context. It defaults
+// to the trace ID of a sampled OpenTelemetry span. IDs which are not valid UTF-8, or too long for an exemplar, are
+// dropped and the observation is recorded without one.
+func WithTraceID(traceID func(ctx context.Context) (string, bool)) Option {
+	return func(o *options) {
+		o.traceID = traceID
+	}
+}
+
+// WithDefaultInvoker sets the invoker used by ObserveContext when the context does not carry one. It defaults to
+// unknown.
+func WithDefaultInvoker(invoker string) Option {
+	return func(o *options) {
+		o.defaultInvoker = invoker
+	}
+}
+
+// WithErrorClassifier sets which errors count towards the error total. isError is only called with non-nil errors
+// and defaults to IsError.
+func WithErrorClassifier(isError func(err error) bool) Option {
+	return func(o *options) {
+		o.isError = isError
+	}
+}
+
+// WithErrorReason adds a reason label to the error total, such as timeout or throttled. mappers are tried in turn
+// before Reason, with any error that is not recognised labelled as other. Reasons should be kept to a small, fixed set.
+// It changes the labels of the error total, so must be given to every Recorder of a Family sharing a registerer, or
+// none.
+func WithErrorReason(mappers ...ReasonMapper) Option {
+	return func(o *options) {
+		o.reason = true
+		o.reasonMappers = append(o.reasonMappers, mappers...)
+	}
+}
+
+// WithInFlight adds a <name>_in_flight gauge of the calls currently being made, labelled by the Family's Labels.
+func WithInFlight() Option {
+	return func(o *options) {
+		o.inFlight = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		registerer:     prometheus.DefaultRegisterer,
+		traceID:        spanTraceID,
+		defaultInvoker: "unknown",
+		isError:        IsError,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+func spanTraceID(ctx context.Context) (string, bool) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() || !spanContext.IsSampled() {
+		return "", false
+	}
+
+	return spanContext.TraceID().String(), true
+}
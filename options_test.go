@@ -0,0 +1,268 @@
+package promred
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecorder_WithRegisterer(t *testing.T) {
+	tests := []struct {
+		name                       string
+		givenRecorders             int
+		expectedMetricCount        int
+		expectedDefaultMetricCount int
+	}{
+		{
+			name:                       "given two recorders on the same registry, expect them to share 2 series and none on the default registerer",
+			givenRecorders:             2,
+			expectedMetricCount:        2,
+			expectedDefaultMetricCount: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			for i := 0; i < test.givenRecorders; i++ {
+				r := NewRecorder(Family{Name: "registerer", Noun: "operations"}, WithRegisterer(reg))
+
+				_ = r.Observe(context.Background(), "invoker", "operation", func() error {
+					return nil
+				})
+			}
+
+			names := []string{"registerer_operation_total", "registerer_error_total", "registerer_duration_seconds"}
+
+			actualMetricCount, err := testutil.GatherAndCount(reg, names...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualMetricCount, test.expectedMetricCount) {
+				t.Fatal(cmp.Diff(actualMetricCount, test.expectedMetricCount))
+			}
+
+			actualDefaultMetricCount, err := testutil.GatherAndCount(prometheus.DefaultGatherer, names...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualDefaultMetricCount, test.expectedDefaultMetricCount) {
+				t.Fatal(cmp.Diff(actualDefaultMetricCount, test.expectedDefaultMetricCount))
+			}
+		})
+	}
+}
+
+func TestRecorder_WithNamespaceSubsystemAndConstLabels(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenNamespace     string
+		givenSubsystem     string
+		givenConstLabels   prometheus.Labels
+		expectedMetricName string
+		expectedExposition string
+	}{
+		{
+			name:               "given namespace, subsystem and const labels, expect them on the operation count",
+			givenNamespace:     "team",
+			givenSubsystem:     "service",
+			givenConstLabels:   prometheus.Labels{"env": "test"},
+			expectedMetricName: "team_service_test_operation_total",
+			expectedExposition: `
+# HELP team_service_test_operation_total The number of operations
+# TYPE team_service_test_operation_total counter
+team_service_test_operation_total{env="test",invoker="invoker",operation="operation"} 1
+`,
+		},
+		{
+			name:               "given namespace only, expect it alone to prefix the operation count",
+			givenNamespace:     "team",
+			expectedMetricName: "team_test_operation_total",
+			expectedExposition: `
+# HELP team_test_operation_total The number of operations
+# TYPE team_test_operation_total counter
+team_test_operation_total{invoker="invoker",operation="operation"} 1
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			opts := []Option{
+				WithRegisterer(reg),
+				WithNamespace(test.givenNamespace),
+				WithSubsystem(test.givenSubsystem),
+				WithConstLabels(test.givenConstLabels),
+			}
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, opts...)
+
+			_ = r.Observe(context.Background(), "invoker", "operation", func() error {
+				return nil
+			})
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.expectedExposition), test.expectedMetricName)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRecorder_WithBucketsAndNativeHistogramBucketFactor(t *testing.T) {
+	tests := []struct {
+		name                    string
+		givenBuckets            []float64
+		givenNativeBucketFactor float64
+		expectedBucketCount     int
+		expectedNativeHistogram bool
+	}{
+		{
+			name:                "given no buckets, expect the default buckets",
+			expectedBucketCount: len(prometheus.DefBuckets),
+		},
+		{
+			name:                "given buckets, expect only those buckets",
+			givenBuckets:        []float64{0.001, 0.005, 0.01},
+			expectedBucketCount: 3,
+		},
+		{
+			name:                    "given native histogram bucket factor, expect only a native histogram",
+			givenNativeBucketFactor: 1.1,
+			expectedBucketCount:     0,
+			expectedNativeHistogram: true,
+		},
+		{
+			name:                    "given buckets and native histogram bucket factor, expect both",
+			givenBuckets:            prometheus.DefBuckets,
+			givenNativeBucketFactor: 1.1,
+			expectedBucketCount:     len(prometheus.DefBuckets),
+			expectedNativeHistogram: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			opts := []Option{WithRegisterer(reg)}
+
+			if test.givenBuckets != nil {
+				opts = append(opts, WithBuckets(test.givenBuckets))
+			}
+
+			if test.givenNativeBucketFactor != 0 {
+				opts = append(opts, WithNativeHistogramBucketFactor(test.givenNativeBucketFactor))
+			}
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, opts...)
+
+			_ = r.Observe(context.Background(), "invoker", "operation", func() error {
+				return nil
+			})
+
+			histogram, err := testtool.GetHistogram(reg, "test_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(len(histogram.GetBucket()), test.expectedBucketCount) {
+				t.Fatal(cmp.Diff(len(histogram.GetBucket()), test.expectedBucketCount))
+			}
+
+			actualNativeHistogram := histogram.Schema != nil
+			if !cmp.Equal(actualNativeHistogram, test.expectedNativeHistogram) {
+				t.Fatal(cmp.Diff(actualNativeHistogram, test.expectedNativeHistogram))
+			}
+		})
+	}
+}
+
+func TestRecorder_WithTraceID(t *testing.T) {
+	sampledSpan := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	tests := []struct {
+		name             string
+		givenContext     context.Context
+		givenOptions     []Option
+		expectedTraceIDs []string
+	}{
+		{
+			name:             "given sampled span, expect its trace ID on each exemplar",
+			givenContext:     trace.ContextWithSpanContext(context.Background(), sampledSpan),
+			expectedTraceIDs: []string{sampledSpan.TraceID().String(), sampledSpan.TraceID().String()},
+		},
+		{
+			name:         "given no span, expect no exemplars",
+			givenContext: context.Background(),
+		},
+		{
+			name:         "given trace ID func, expect its trace ID on each exemplar",
+			givenContext: context.Background(),
+			givenOptions: []Option{
+				WithTraceID(func(_ context.Context) (string, bool) {
+					return "abc", true
+				}),
+			},
+			expectedTraceIDs: []string{"abc", "abc"},
+		},
+		{
+			name:         "given trace ID func returning an ID longer than exemplars allow, expect no exemplars",
+			givenContext: context.Background(),
+			givenOptions: []Option{
+				WithTraceID(func(_ context.Context) (string, bool) {
+					return strings.Repeat("a", prometheus.ExemplarMaxRunes), true
+				}),
+			},
+		},
+		{
+			name:         "given trace ID func returning invalid UTF-8, expect no exemplars",
+			givenContext: context.Background(),
+			givenOptions: []Option{
+				WithTraceID(func(_ context.Context) (string, bool) {
+					return "\xff", true
+				}),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, append(test.givenOptions, WithRegisterer(reg))...)
+
+			err := r.Observe(test.givenContext, "invoker", "operation", func() error {
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actualTraceIDs, err := testtool.GetExemplarTraceIDs(reg, "test_operation_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			durationTraceIDs, err := testtool.GetExemplarTraceIDs(reg, "test_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actualTraceIDs = append(actualTraceIDs, durationTraceIDs...)
+
+			if !cmp.Equal(actualTraceIDs, test.expectedTraceIDs) {
+				t.Fatal(cmp.Diff(actualTraceIDs, test.expectedTraceIDs))
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package promred
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const scope = "github.com/jamieaitken/promred"
+
+type otelCounter struct {
+	counter    metric.Float64Counter
+	attributes attributes
+}
+
+func newOTelCounter(o options, d desc) otelCounter {
+	c, err := meter(o).Float64Counter(otelName(o, d), metric.WithDescription(d.help))
+	if err != nil {
+		panic(err)
+	}
+
+	return otelCounter{
+		counter:    c,
+		attributes: newAttributes(o, d),
+	}
+}
+
+func (c otelCounter) inc(ctx context.Context, lvs []string) {
+	c.counter.Add(ctx, 1, c.attributes.with(lvs))
+}
+
+type otelHistogram struct {
+	histogram  metric.Float64Histogram
+	attributes attributes
+}
+
+func newOTelHistogram(o options, d desc) otelHistogram {
+	buckets := d.buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	h, err := meter(o).Float64Histogram(otelName(o, d),
+		metric.WithDescription(d.help),
+		metric.WithUnit(unit(d)),
+		metric.WithExplicitBucketBoundaries(buckets...),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return otelHistogram{
+		histogram:  h,
+		attributes: newAttributes(o, d),
+	}
+}
+
+func (h otelHistogram) observe(ctx context.Context, value float64, lvs []string) {
+	h.histogram.Record(ctx, value, h.attributes.with(lvs))
+}
+
+type otelGauge struct {
+	counter    metric.Float64UpDownCounter
+	attributes attributes
+}
+
+func newOTelGauge(o options, d desc) otelGauge {
+	c, err := meter(o).Float64UpDownCounter(otelName(o, d), metric.WithDescription(d.help))
+	if err != nil {
+		panic(err)
+	}
+
+	return otelGauge{
+		counter:    c,
+		attributes: newAttributes(o, d),
+	}
+}
+
+func (g otelGauge) add(ctx context.Context, delta float64, lvs []string) {
+	g.counter.Add(ctx, delta, g.attributes.with(lvs))
+}
+
+type attributes struct {
+	constant []attribute.KeyValue
+	keys     []attribute.Key
+}
+
+func newAttributes(o options, d desc) attributes {
+	constant := make([]attribute.KeyValue, 0, len(o.constLabels))
+	for name, value := range o.constLabels {
+		constant = append(constant, attribute.String(name, value))
+	}
+
+	sort.Slice(constant, func(i, j int) bool {
+		return constant[i].Key < constant[j].Key
+	})
+
+	keys := make([]attribute.Key, len(d.labels))
+	for i, label := range d.labels {
+		keys[i] = attribute.Key(label)
+	}
+
+	return attributes{
+		constant: constant,
+		keys:     keys,
+	}
+}
+
+func (a attributes) with(lvs []string) metric.MeasurementOption {
+	kvs := make([]attribute.KeyValue, 0, len(a.constant)+len(a.keys))
+	kvs = append(kvs, a.constant...)
+
+	for i, key := range a.keys {
+		kvs = append(kvs, key.String(lvs[i]))
+	}
+
+	return metric.WithAttributes(kvs...)
+}
+
+func meter(o options) metric.Meter {
+	return o.meterProvider.Meter(scope)
+}
+
+func otelName(o options, d desc) string {
+	return prometheus.BuildFQName(o.namespace, o.subsystem, d.name)
+}
+
+func unit(d desc) string {
+	switch {
+	case strings.HasSuffix(d.name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(d.name, "_bytes"):
+		return "By"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,93 @@
+package promred
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestRecorder_WithMeterProvider(t *testing.T) {
+	tests := []struct {
+		name                        string
+		givenRegisterer             prometheus.Registerer
+		givenOptions                []Option
+		givenCalls                  []error
+		expectedOperationCount      float64
+		expectedErrorCount          float64
+		expectedDurationCount       uint64
+		expectedDurationBucketCount int
+	}{
+		{
+			name:                        "given failure after success, expect operation count to be 2 and error count to be 1",
+			givenRegisterer:             prometheus.NewRegistry(),
+			givenCalls:                  []error{nil, errFail},
+			expectedOperationCount:      2,
+			expectedErrorCount:          1,
+			expectedDurationCount:       2,
+			expectedDurationBucketCount: len(prometheus.DefBuckets) + 1,
+		},
+		{
+			name:                        "given nil registerer and buckets, expect the instruments to use the buckets",
+			givenOptions:                []Option{WithBuckets([]float64{0.1, 1})},
+			givenCalls:                  []error{errFail},
+			expectedOperationCount:      1,
+			expectedErrorCount:          1,
+			expectedDurationCount:       1,
+			expectedDurationBucketCount: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+			opts := append([]Option{WithRegisterer(test.givenRegisterer), WithMeterProvider(provider)}, test.givenOptions...)
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, opts...)
+
+			for _, givenErr := range test.givenCalls {
+				_ = r.Observe(context.Background(), "invoker", "operation", func() error {
+					return givenErr
+				})
+			}
+
+			attributes := []attribute.KeyValue{attribute.String("invoker", "invoker"), attribute.String("operation", "operation")}
+
+			actualOperationCount, err := testtool.GetOTelSumValue(reader, "test_operation_total", attributes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetOTelSumValue(reader, "test_error_total", attributes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+
+			actualDuration, err := testtool.GetOTelHistogram(reader, "test_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualDuration.Count, test.expectedDurationCount) {
+				t.Fatal(cmp.Diff(actualDuration.Count, test.expectedDurationCount))
+			}
+
+			if !cmp.Equal(len(actualDuration.BucketCounts), test.expectedDurationBucketCount) {
+				t.Fatal(cmp.Diff(len(actualDuration.BucketCounts), test.expectedDurationBucketCount))
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package promred
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type prometheusCounter struct {
+	vec     *prometheus.CounterVec
+	traceID func(ctx context.Context) (string, bool)
+}
+
+func newPrometheusCounter(o options, d desc) prometheusCounter {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   o.namespace,
+		Subsystem:   o.subsystem,
+		Name:        d.name,
+		Help:        d.help,
+		ConstLabels: o.constLabels,
+	}, d.labels)
+
+	return prometheusCounter{
+		vec:     register(o.registerer, c, d).(*prometheus.CounterVec),
+		traceID: o.traceID,
+	}
+}
+
+func (c prometheusCounter) inc(ctx context.Context, lvs []string) {
+	counter := c.vec.WithLabelValues(lvs...)
+
+	adder, ok := counter.(prometheus.ExemplarAdder)
+	exemplar := exemplarFor(ctx, c.traceID)
+
+	if !ok || exemplar == nil {
+		counter.Inc()
+
+		return
+	}
+
+	adder.AddWithExemplar(1, exemplar)
+}
+
+type prometheusHistogram struct {
+	vec     *prometheus.HistogramVec
+	traceID func(ctx context.Context) (string, bool)
+}
+
+func newPrometheusHistogram(o options, d desc) prometheusHistogram {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                   o.namespace,
+		Subsystem:                   o.subsystem,
+		Name:                        d.name,
+		Help:                        d.help,
+		ConstLabels:                 o.constLabels,
+		Buckets:                     d.buckets,
+		NativeHistogramBucketFactor: o.nativeHistogramBucketFactor,
+	}, d.labels)
+
+	return prometheusHistogram{
+		vec:     register(o.registerer, h, d).(*prometheus.HistogramVec),
+		traceID: o.traceID,
+	}
+}
+
+func (h prometheusHistogram) observe(ctx context.Context, value float64, lvs []string) {
+	observer := h.vec.WithLabelValues(lvs...)
+
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	exemplar := exemplarFor(ctx, h.traceID)
+
+	if !ok || exemplar == nil {
+		observer.Observe(value)
+
+		return
+	}
+
+	exemplarObserver.ObserveWithExemplar(value, exemplar)
+}
+
+type prometheusGauge struct {
+	vec *prometheus.GaugeVec
+}
+
+func newPrometheusGauge(o options, d desc) prometheusGauge {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   o.namespace,
+		Subsystem:   o.subsystem,
+		Name:        d.name,
+		Help:        d.help,
+		ConstLabels: o.constLabels,
+	}, d.labels)
+
+	return prometheusGauge{
+		vec: register(o.registerer, g, d).(*prometheus.GaugeVec),
+	}
+}
+
+func (g prometheusGauge) add(_ context.Context, delta float64, lvs []string) {
+	g.vec.WithLabelValues(lvs...).Add(delta)
+}
+
+// register registers c, described by d, with reg, or returns the collector already registered in its place, so that
+// constructors sharing a registerer share their metrics. The existing collector keeps the buckets it was created with,
+// whatever those of c. A metric of the same name but different labels cannot be registered, so register panics, as it
+// means constructors sharing reg were given different options which change labels.
+func register(reg prometheus.Registerer, c prometheus.Collector, d desc) prometheus.Collector {
+	err := reg.Register(c)
+	if err == nil {
+		return c
+	}
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return alreadyRegistered.ExistingCollector
+	}
+
+	panic(fmt.Errorf("promred: registering %s labelled by %s: %w. Constructors sharing a registerer must be given the same "+
+		"options which change labels, such as WithStatusClass, WithHost, WithStatusOnDuration and WithErrorReason, or a "+
+		"registerer each", d.name, strings.Join(d.labels, ", "), err))
+}
+
+// exemplarLabel is the name of the label carrying the trace ID of an exemplar.
+const exemplarLabel = "trace_id"
+
+// exemplarFor returns the exemplar labels for the trace ID carried by ctx, or nil if there is none or it would make
+// client_golang panic, as it does for exemplar labels which are not valid UTF-8 or longer than ExemplarMaxRunes.
+func exemplarFor(ctx context.Context, traceID func(ctx context.Context) (string, bool)) prometheus.Labels {
+	id, ok := traceID(ctx)
+	if !ok {
+		return nil
+	}
+
+	if !utf8.ValidString(id) || utf8.RuneCountInString(exemplarLabel)+utf8.RuneCountInString(id) > prometheus.ExemplarMaxRunes {
+		return nil
+	}
+
+	return prometheus.Labels{exemplarLabel: id}
+}
@@ -0,0 +1,73 @@
+package promred
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// The reasons an error can be labelled with.
+const (
+	ReasonTimeout           = "timeout"
+	ReasonCanceled          = "canceled"
+	ReasonConnectionRefused = "connection_refused"
+	ReasonThrottled         = "throttled"
+	ReasonNotFound          = "not_found"
+	ReasonOther             = "other"
+)
+
+// ReasonMapper returns the reason err failed, or false if it does not recognise err.
+type ReasonMapper func(err error) (string, bool)
+
+// Reason is the built-in ReasonMapper for errors common to every dependency, which is tried after any others.
+func Reason(err error) (string, bool) {
+	var (
+		statusErr *StatusError
+		netErr    net.Error
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ReasonCanceled, true
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ReasonTimeout, true
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ReasonConnectionRefused, true
+	case errors.As(err, &statusErr):
+		return statusReason(statusErr.StatusCode)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ReasonTimeout, true
+	}
+
+	return "", false
+}
+
+func statusReason(statusCode int) (string, bool) {
+	switch statusCode {
+	case http.StatusNotFound:
+		return ReasonNotFound, true
+	case http.StatusTooManyRequests:
+		return ReasonThrottled, true
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ReasonTimeout, true
+	}
+
+	return "", false
+}
+
+func reasonFor(err error, mappers []ReasonMapper) string {
+	for _, mapper := range mappers {
+		if reason, ok := mapper(err); ok {
+			return reason
+		}
+	}
+
+	if reason, ok := Reason(err); ok {
+		return reason
+	}
+
+	return ReasonOther
+}
@@ -0,0 +1,139 @@
+package promred
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenError     error
+		expectedReason string
+		expectedOk     bool
+	}{
+		{
+			name:           "given cancelled context, expect canceled",
+			givenError:     fmt.Errorf("get: %w", context.Canceled),
+			expectedReason: ReasonCanceled,
+			expectedOk:     true,
+		},
+		{
+			name:           "given exceeded deadline, expect timeout",
+			givenError:     context.DeadlineExceeded,
+			expectedReason: ReasonTimeout,
+			expectedOk:     true,
+		},
+		{
+			name:           "given net error timeout, expect timeout",
+			givenError:     &net.DNSError{IsTimeout: true},
+			expectedReason: ReasonTimeout,
+			expectedOk:     true,
+		},
+		{
+			name:           "given refused connection, expect connection_refused",
+			givenError:     &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED},
+			expectedReason: ReasonConnectionRefused,
+			expectedOk:     true,
+		},
+		{
+			name:           "given status error of 429, expect throttled",
+			givenError:     &StatusError{StatusCode: 429},
+			expectedReason: ReasonThrottled,
+			expectedOk:     true,
+		},
+		{
+			name:           "given status error of 404, expect not_found",
+			givenError:     &StatusError{StatusCode: 404},
+			expectedReason: ReasonNotFound,
+			expectedOk:     true,
+		},
+		{
+			name:       "given status error of 500, expect no reason",
+			givenError: &StatusError{StatusCode: 500},
+		},
+		{
+			name:       "given unrecognised error, expect no reason",
+			givenError: errFail,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualReason, actualOk := Reason(test.givenError)
+
+			if !cmp.Equal(actualReason, test.expectedReason) {
+				t.Fatal(cmp.Diff(actualReason, test.expectedReason))
+			}
+
+			if !cmp.Equal(actualOk, test.expectedOk) {
+				t.Fatal(cmp.Diff(actualOk, test.expectedOk))
+			}
+		})
+	}
+}
+
+func TestRecorder_Record_WithErrorReason(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenMappers       []ReasonMapper
+		givenError         error
+		expectedExposition string
+	}{
+		{
+			name:       "given recognised error, expect its reason",
+			givenError: context.DeadlineExceeded,
+			expectedExposition: `
+# HELP test_error_total The number of those operations that have failed
+# TYPE test_error_total counter
+test_error_total{invoker="invoker",operation="operation",reason="timeout"} 1
+`,
+		},
+		{
+			name:       "given unrecognised error, expect other",
+			givenError: errFail,
+			expectedExposition: `
+# HELP test_error_total The number of those operations that have failed
+# TYPE test_error_total counter
+test_error_total{invoker="invoker",operation="operation",reason="other"} 1
+`,
+		},
+		{
+			name: "given mapper recognising error, expect its reason",
+			givenMappers: []ReasonMapper{
+				func(err error) (string, bool) {
+					return "invalid", err == errFail
+				},
+			},
+			givenError: errFail,
+			expectedExposition: `
+# HELP test_error_total The number of those operations that have failed
+# TYPE test_error_total counter
+test_error_total{invoker="invoker",operation="operation",reason="invalid"} 1
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, WithRegisterer(reg),
+				WithErrorReason(test.givenMappers...))
+
+			r.Record(context.Background(), time.Millisecond, test.givenError, "invoker", "operation")
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.expectedExposition), "test_error_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
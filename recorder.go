@@ -0,0 +1,229 @@
+package promred
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Family describes the <Name>_operation_total, <Name>_error_total and <Name>_duration_seconds metrics a Recorder
+// creates, along with <Name>_in_flight when WithInFlight is given.
+type Family struct {
+	// Name prefixes each metric, e.g. redis.
+	Name string
+	// Noun is what is being counted, as used in the help text, e.g. operations.
+	Noun string
+	// Labels are known before a call is made and label each metric. They default to invoker and operation.
+	Labels []string
+	// ResultLabels are only known once a call has completed, such as a status code, and label the operation and
+	// error counts after Labels.
+	ResultLabels []string
+	// ResultLabelsOnDuration also labels the duration histogram with ResultLabels, so that, say, the latency of
+	// successful calls can be told apart from that of failed ones.
+	ResultLabelsOnDuration bool
+	// InFlightLabels label the in-flight gauge in place of Labels, for when some of Labels are only known once a call
+	// has been made, such as the route a router matched.
+	InFlightLabels []string
+}
+
+func (f Family) labels() []string {
+	if f.Labels == nil {
+		return []string{"invoker", "operation"}
+	}
+
+	return f.Labels
+}
+
+func (f Family) resultLabels() []string {
+	labels := f.labels()
+
+	return append(labels[:len(labels):len(labels)], f.ResultLabels...)
+}
+
+func (f Family) inFlightLabels() []string {
+	if f.InFlightLabels == nil {
+		return f.labels()
+	}
+
+	return f.InFlightLabels
+}
+
+func (f Family) durationLabels() []string {
+	if f.ResultLabelsOnDuration {
+		return f.resultLabels()
+	}
+
+	return f.labels()
+}
+
+// Recorder records the rate, errors and duration of calls made to a dependency.
+type Recorder struct {
+	family         Family
+	options        options
+	labelCount     int
+	operationCount counter
+	errorCount     counter
+	duration       histogram
+	inFlight       gauge
+	defaultInvoker string
+	isError        func(err error) bool
+	reason         bool
+	reasonMappers  []ReasonMapper
+}
+
+// NewRecorder creates the metrics described by family, or reuses them if they are already registered.
+func NewRecorder(family Family, opts ...Option) *Recorder {
+	o := newOptions(opts)
+
+	var inFlight gauge
+	if o.inFlight {
+		inFlight = withInFlight(family, o)
+	}
+
+	return &Recorder{
+		family:         family,
+		options:        o,
+		labelCount:     len(family.durationLabels()),
+		operationCount: withRate(family, o),
+		errorCount:     withError(family, o),
+		duration:       withDuration(family, o),
+		inFlight:       inFlight,
+		defaultInvoker: o.defaultInvoker,
+		isError:        o.isError,
+		reason:         o.reason,
+		reasonMappers:  o.reasonMappers,
+	}
+}
+
+// Observe calls fn and records it against invoker and operation, returning the error from fn. If fn panics, only its
+// duration is recorded before the panic carries on. It should only be used with a Family labelled by invoker and
+// operation.
+func (r *Recorder) Observe(ctx context.Context, invoker, operation string, fn func() error) error {
+	done := r.InFlight(invoker, operation)
+	defer done()
+
+	start := time.Now()
+
+	returned := false
+	defer func() {
+		if !returned {
+			r.RecordDuration(ctx, time.Since(start), invoker, operation)
+		}
+	}()
+
+	err := fn()
+	returned = true
+
+	r.Record(ctx, time.Since(start), err, invoker, operation)
+
+	return err
+}
+
+// ObserveContext is Observe with the invoker read from ctx, as set by WithInvoker.
+func (r *Recorder) ObserveContext(ctx context.Context, operation string, fn func() error) error {
+	return r.Observe(ctx, r.Invoker(ctx), operation, fn)
+}
+
+// Invoker returns the invoker carried by ctx, or the default invoker if there is none.
+func (r *Recorder) Invoker(ctx context.Context) string {
+	invoker, ok := InvokerFromContext(ctx)
+	if !ok {
+		return r.defaultInvoker
+	}
+
+	return invoker
+}
+
+// InFlight marks a call labelled by the Family's InFlightLabels, or its Labels if there are none, as in flight,
+// returning a func which marks it as done. It does nothing unless WithInFlight is given.
+func (r *Recorder) InFlight(lvs ...string) func() {
+	if r.inFlight == nil {
+		return func() {}
+	}
+
+	ctx := context.Background()
+
+	r.inFlight.add(ctx, 1, lvs)
+
+	return func() {
+		r.inFlight.add(ctx, -1, lvs)
+	}
+}
+
+// Record records a call that took d, which failed if err is not nil and counts as an error. lvs are the values of the
+// Family's Labels followed by those of its ResultLabels.
+func (r *Recorder) Record(ctx context.Context, d time.Duration, err error, lvs ...string) {
+	r.duration.observe(ctx, d.Seconds(), lvs[:r.labelCount])
+
+	r.operationCount.inc(ctx, lvs)
+
+	if !r.IsError(err) {
+		return
+	}
+
+	if r.reason {
+		lvs = append(lvs[:len(lvs):len(lvs)], reasonFor(err, r.reasonMappers))
+	}
+
+	r.errorCount.inc(ctx, lvs)
+}
+
+// IsError reports whether err counts as an error, as decided by the error classifier. A nil err never does.
+func (r *Recorder) IsError(err error) bool {
+	return err != nil && r.isError(err)
+}
+
+// RecordDuration records only the duration of a call that took d, such as one which panicked and so has no result to
+// count. lvs are as given to Record.
+func (r *Recorder) RecordDuration(ctx context.Context, d time.Duration, lvs ...string) {
+	r.duration.observe(ctx, d.Seconds(), lvs[:r.labelCount])
+}
+
+// Histogram creates a histogram named <Name>_<name> alongside the Family's metrics, such as one of request sizes, with
+// the same options but buckets of its own. A nil buckets uses those of the duration histogram.
+func (r *Recorder) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = r.options.buckets
+	}
+
+	return &Histogram{
+		histogram: newHistogram(r.options, desc{
+			name:    fmt.Sprintf("%s_%s", r.family.Name, name),
+			help:    help,
+			labels:  labels,
+			buckets: buckets,
+		}),
+	}
+}
+
+// Histogram is a histogram created by Recorder.Histogram.
+type Histogram struct {
+	histogram histogram
+}
+
+// Observe records value, where lvs are the values of the labels the Histogram was created with.
+func (h *Histogram) Observe(ctx context.Context, value float64, lvs ...string) {
+	h.histogram.observe(ctx, value, lvs)
+}
+
+// Counter creates a counter named <Name>_<name> alongside the Family's metrics, such as one of panics, with the same
+// options.
+func (r *Recorder) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{
+		counter: newCounter(r.options, desc{
+			name:   fmt.Sprintf("%s_%s", r.family.Name, name),
+			help:   help,
+			labels: labels,
+		}),
+	}
+}
+
+// Counter is a counter created by Recorder.Counter.
+type Counter struct {
+	counter counter
+}
+
+// Inc increments the count of lvs, the values of the labels the Counter was created with.
+func (c *Counter) Inc(ctx context.Context, lvs ...string) {
+	c.counter.inc(ctx, lvs)
+}
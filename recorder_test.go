@@ -0,0 +1,570 @@
+package promred
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecorder_Observe(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenCalls             []error
+		expectedError          error
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenCalls:             []error{nil},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:                   "given failure after success, expect operation count to be 2 and error count to be 1",
+			givenCalls:             []error{nil, errFail},
+			expectedError:          errFail,
+			expectedOperationCount: 2,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			var err error
+
+			for _, givenErr := range test.givenCalls {
+				r := NewRecorder(Family{Name: "test", Noun: "operations"}, WithRegisterer(reg))
+
+				err = r.Observe(context.Background(), "invoker", "operation", func() error {
+					return givenErr
+				})
+			}
+
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("expected %v, got %v", test.expectedError, err)
+			}
+
+			labels := prometheus.Labels{"invoker": "invoker", "operation": "operation"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "test_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "test_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestRecorder_Observe_Panic(t *testing.T) {
+	tests := []struct {
+		name                   string
+		expectedDurationCount  uint64
+		expectedOperationCount int
+	}{
+		{
+			name:                   "given fn panics, expect the duration to be recorded but not the operation",
+			expectedDurationCount:  1,
+			expectedOperationCount: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, WithRegisterer(reg))
+
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatal("expected the panic to carry on")
+					}
+				}()
+
+				_ = r.Observe(context.Background(), "invoker", "operation", func() error {
+					panic("fail")
+				})
+			}()
+
+			actualHistogram, err := testtool.GetHistogram(reg, "test_duration_seconds")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualHistogram.GetSampleCount(), test.expectedDurationCount) {
+				t.Fatal(cmp.Diff(actualHistogram.GetSampleCount(), test.expectedDurationCount))
+			}
+
+			actualOperationCount, err := testutil.GatherAndCount(reg, "test_operation_total")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+		})
+	}
+}
+
+func TestRecorder_Record(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenFamily        Family
+		givenError         error
+		givenLabelValues   []string
+		expectedExposition string
+	}{
+		{
+			name: "given result labels, expect them on the counts but not the duration",
+			givenFamily: Family{
+				Name:         "test",
+				Noun:         "requests",
+				Labels:       []string{"path"},
+				ResultLabels: []string{"status_code"},
+			},
+			givenError:       errFail,
+			givenLabelValues: []string{"/test", "500"},
+			expectedExposition: `
+# HELP test_duration_seconds The amount of time those requests take
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{path="/test",le="1"} 1
+test_duration_seconds_bucket{path="/test",le="+Inf"} 1
+test_duration_seconds_sum{path="/test"} 0.5
+test_duration_seconds_count{path="/test"} 1
+# HELP test_error_total The number of those requests that have failed
+# TYPE test_error_total counter
+test_error_total{path="/test",status_code="500"} 1
+# HELP test_operation_total The number of requests
+# TYPE test_operation_total counter
+test_operation_total{path="/test",status_code="500"} 1
+`,
+		},
+		{
+			name: "given result labels on duration, expect them on the counts and the duration",
+			givenFamily: Family{
+				Name:                   "test",
+				Noun:                   "requests",
+				Labels:                 []string{"path"},
+				ResultLabels:           []string{"status_code"},
+				ResultLabelsOnDuration: true,
+			},
+			givenError:       errFail,
+			givenLabelValues: []string{"/test", "500"},
+			expectedExposition: `
+# HELP test_duration_seconds The amount of time those requests take
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{path="/test",status_code="500",le="1"} 1
+test_duration_seconds_bucket{path="/test",status_code="500",le="+Inf"} 1
+test_duration_seconds_sum{path="/test",status_code="500"} 0.5
+test_duration_seconds_count{path="/test",status_code="500"} 1
+# HELP test_error_total The number of those requests that have failed
+# TYPE test_error_total counter
+test_error_total{path="/test",status_code="500"} 1
+# HELP test_operation_total The number of requests
+# TYPE test_operation_total counter
+test_operation_total{path="/test",status_code="500"} 1
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(test.givenFamily, WithRegisterer(reg), WithBuckets([]float64{1}))
+
+			r.Record(context.Background(), 500*time.Millisecond, test.givenError, test.givenLabelValues...)
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.expectedExposition))
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRecorder_IsError(t *testing.T) {
+	tests := []struct {
+		name            string
+		givenClassifier func(err error) bool
+		givenError      error
+		expected        bool
+	}{
+		{
+			name:       "given no error, expect false",
+			givenError: nil,
+			expected:   false,
+		},
+		{
+			name:       "given an error, expect true",
+			givenError: errors.New("fail"),
+			expected:   true,
+		},
+		{
+			name:       "given a cancelled context, expect false",
+			givenError: context.Canceled,
+			expected:   false,
+		},
+		{
+			name:            "given an error the classifier ignores, expect false",
+			givenClassifier: func(err error) bool { return false },
+			givenError:      errors.New("fail"),
+			expected:        false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := []Option{WithRegisterer(prometheus.NewRegistry())}
+			if test.givenClassifier != nil {
+				opts = append(opts, WithErrorClassifier(test.givenClassifier))
+			}
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations", Labels: []string{"operation"}}, opts...)
+
+			actual := r.IsError(test.givenError)
+
+			if !cmp.Equal(actual, test.expected) {
+				t.Fatal(cmp.Diff(actual, test.expected))
+			}
+		})
+	}
+}
+
+func TestRecorder_RecordDuration(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenFamily        Family
+		givenLabelValues   []string
+		expectedExposition string
+	}{
+		{
+			name: "given result labels, expect only the duration to be recorded",
+			givenFamily: Family{
+				Name:         "test",
+				Noun:         "requests",
+				Labels:       []string{"path"},
+				ResultLabels: []string{"status_code"},
+			},
+			givenLabelValues: []string{"/test", "500"},
+			expectedExposition: `
+# HELP test_duration_seconds The amount of time those requests take
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{path="/test",le="1"} 1
+test_duration_seconds_bucket{path="/test",le="+Inf"} 1
+test_duration_seconds_sum{path="/test"} 0.5
+test_duration_seconds_count{path="/test"} 1
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(test.givenFamily, WithRegisterer(reg), WithBuckets([]float64{1}))
+
+			r.RecordDuration(context.Background(), 500*time.Millisecond, test.givenLabelValues...)
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.expectedExposition))
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRecorder_InFlight(t *testing.T) {
+	tests := []struct {
+		name                  string
+		givenOptions          []Option
+		expectedDuringCall    int
+		expectedAfterCall     int
+		expectedGaugeGathered int
+	}{
+		{
+			name:                  "given WithInFlight, expect the gauge to be 1 during the call and 0 after",
+			givenOptions:          []Option{WithInFlight()},
+			expectedDuringCall:    1,
+			expectedAfterCall:     0,
+			expectedGaugeGathered: 1,
+		},
+		{
+			name:                  "given no WithInFlight, expect no gauge",
+			expectedDuringCall:    0,
+			expectedAfterCall:     0,
+			expectedGaugeGathered: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, append(test.givenOptions, WithRegisterer(reg))...)
+
+			labels := prometheus.Labels{"invoker": "invoker", "operation": "operation"}
+
+			var actualDuringCall int
+
+			err := r.Observe(context.Background(), "invoker", "operation", func() error {
+				var err error
+				actualDuringCall, err = testtool.GetGaugeValue(reg, "test_in_flight", labels)
+
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualDuringCall, test.expectedDuringCall) {
+				t.Fatal(cmp.Diff(actualDuringCall, test.expectedDuringCall))
+			}
+
+			actualAfterCall, err := testtool.GetGaugeValue(reg, "test_in_flight", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualAfterCall, test.expectedAfterCall) {
+				t.Fatal(cmp.Diff(actualAfterCall, test.expectedAfterCall))
+			}
+
+			actualGaugeGathered, err := testutil.GatherAndCount(reg, "test_in_flight")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualGaugeGathered, test.expectedGaugeGathered) {
+				t.Fatal(cmp.Diff(actualGaugeGathered, test.expectedGaugeGathered))
+			}
+		})
+	}
+}
+
+func TestRecorder_InFlight_Labels(t *testing.T) {
+	tests := []struct {
+		name             string
+		givenFamily      Family
+		givenLabelValues []string
+		expectedLabels   prometheus.Labels
+	}{
+		{
+			name:             "given no in-flight labels, expect the gauge to be labelled by Labels",
+			givenFamily:      Family{Name: "test", Noun: "requests", Labels: []string{"path", "http_method"}},
+			givenLabelValues: []string{"/test", "GET"},
+			expectedLabels:   prometheus.Labels{"path": "/test", "http_method": "GET"},
+		},
+		{
+			name: "given in-flight labels, expect the gauge to be labelled by them alone",
+			givenFamily: Family{
+				Name:           "test",
+				Noun:           "requests",
+				Labels:         []string{"path", "http_method"},
+				InFlightLabels: []string{"http_method"},
+			},
+			givenLabelValues: []string{"GET"},
+			expectedLabels:   prometheus.Labels{"http_method": "GET"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(test.givenFamily, WithRegisterer(reg), WithInFlight())
+
+			done := r.InFlight(test.givenLabelValues...)
+
+			actualDuringCall, err := testtool.GetGaugeValue(reg, "test_in_flight", test.expectedLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done()
+
+			if !cmp.Equal(actualDuringCall, 1) {
+				t.Fatal(cmp.Diff(actualDuringCall, 1))
+			}
+		})
+	}
+}
+
+func TestRecorder_InFlight_Panic(t *testing.T) {
+	tests := []struct {
+		name              string
+		expectedAfterCall int
+	}{
+		{
+			name:              "given fn panics and is recovered upstream, expect the gauge to be 0 after",
+			expectedAfterCall: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, WithRegisterer(reg), WithInFlight())
+
+			func() {
+				defer func() {
+					_ = recover()
+				}()
+
+				_ = r.Observe(context.Background(), "invoker", "operation", func() error {
+					panic("fail")
+				})
+			}()
+
+			labels := prometheus.Labels{"invoker": "invoker", "operation": "operation"}
+
+			actualAfterCall, err := testtool.GetGaugeValue(reg, "test_in_flight", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualAfterCall, test.expectedAfterCall) {
+				t.Fatal(cmp.Diff(actualAfterCall, test.expectedAfterCall))
+			}
+		})
+	}
+}
+
+func TestNewRecorder_ConflictingLabels(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenFamilies []Family
+		givenOptions  [][]Option
+		expectedPanic string
+	}{
+		{
+			name: "given the same family with different labels on one registerer, expect a panic naming the metric",
+			givenFamilies: []Family{
+				{Name: "test", Noun: "requests", Labels: []string{"path"}},
+				{Name: "test", Noun: "requests", Labels: []string{"host", "path"}},
+			},
+			givenOptions:  [][]Option{nil, nil},
+			expectedPanic: "promred: registering test_operation_total labelled by host, path",
+		},
+		{
+			name: "given the same family with and without error reasons on one registerer, expect a panic naming the metric",
+			givenFamilies: []Family{
+				{Name: "test", Noun: "requests"},
+				{Name: "test", Noun: "requests"},
+			},
+			givenOptions:  [][]Option{nil, {WithErrorReason()}},
+			expectedPanic: "promred: registering test_error_total labelled by invoker, operation, reason",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			defer func() {
+				err, _ := recover().(error)
+
+				if err == nil || !strings.Contains(err.Error(), test.expectedPanic) {
+					t.Fatalf("expected a panic containing %q, got %v", test.expectedPanic, err)
+				}
+			}()
+
+			for i, family := range test.givenFamilies {
+				NewRecorder(family, append(test.givenOptions[i], WithRegisterer(reg))...)
+			}
+		})
+	}
+}
+
+func TestRecorder_Histogram(t *testing.T) {
+	tests := []struct {
+		name               string
+		givenOptions       []Option
+		givenValue         float64
+		expectedExposition string
+	}{
+		{
+			name:         "given namespace, expect a prefixed histogram with its own buckets",
+			givenOptions: []Option{WithNamespace("app"), WithBuckets([]float64{1})},
+			givenValue:   150,
+			expectedExposition: `
+# HELP app_test_size_bytes The size of those operations
+# TYPE app_test_size_bytes histogram
+app_test_size_bytes_bucket{operation="operation",le="100"} 0
+app_test_size_bytes_bucket{operation="operation",le="1000"} 1
+app_test_size_bytes_bucket{operation="operation",le="+Inf"} 1
+app_test_size_bytes_sum{operation="operation"} 150
+app_test_size_bytes_count{operation="operation"} 1
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, append(test.givenOptions, WithRegisterer(reg))...)
+
+			h := r.Histogram("size_bytes", "The size of those operations", []float64{100, 1000}, "operation")
+
+			h.Observe(context.Background(), test.givenValue, "operation")
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.expectedExposition), "app_test_size_bytes")
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRecorder_Counter(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenIncs     int
+		expectedCount int
+	}{
+		{
+			name:          "given two increments, expect a count of 2",
+			givenIncs:     2,
+			expectedCount: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			r := NewRecorder(Family{Name: "test", Noun: "operations"}, WithRegisterer(reg))
+
+			c := r.Counter("panic_total", "The number of those operations that have panicked", "operation")
+
+			for i := 0; i < test.givenIncs; i++ {
+				c.Inc(context.Background(), "operation")
+			}
+
+			actualCount, err := testtool.GetCounterValue(reg, "test_panic_total", prometheus.Labels{"operation": "operation"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualCount, test.expectedCount) {
+				t.Fatal(cmp.Diff(actualCount, test.expectedCount))
+			}
+		})
+	}
+}
+
+var errFail = errors.New("fail")
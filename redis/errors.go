@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jamieaitken/promred"
+)
+
+// errPoolTimeout is the message of the error returned when no connection is free, which go-redis does not export.
+const errPoolTimeout = "redis: connection pool timeout"
+
+// IsError is the default error classifier, which extends promred.IsError so that a cache miss, redis.Nil, is not a
+// failure.
+func IsError(err error) bool {
+	return !errors.Is(err, redis.Nil) && promred.IsError(err)
+}
+
+// Reason is the built-in promred.ReasonMapper for redis, under which a cache miss is not_found and an exhausted
+// connection pool is a timeout.
+func Reason(err error) (string, bool) {
+	switch {
+	case errors.Is(err, redis.Nil):
+		return promred.ReasonNotFound, true
+	case err.Error() == errPoolTimeout:
+		return promred.ReasonTimeout, true
+	}
+
+	return "", false
+}
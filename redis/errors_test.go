@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+)
+
+func TestIsError(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenError    error
+		expectedError bool
+	}{
+		{
+			name:          "given error, expect error",
+			givenError:    errors.New("fail"),
+			expectedError: true,
+		},
+		{
+			name:          "given cache miss, expect no error",
+			givenError:    redis.Nil,
+			expectedError: false,
+		},
+		{
+			name:          "given cancelled context, expect no error",
+			givenError:    fmt.Errorf("get: %w", context.Canceled),
+			expectedError: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualError := IsError(test.givenError)
+
+			if !cmp.Equal(actualError, test.expectedError) {
+				t.Fatal(cmp.Diff(actualError, test.expectedError))
+			}
+		})
+	}
+}
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenError     error
+		expectedReason string
+		expectedOk     bool
+	}{
+		{
+			name:           "given cache miss, expect not_found",
+			givenError:     redis.Nil,
+			expectedReason: promred.ReasonNotFound,
+			expectedOk:     true,
+		},
+		{
+			name:           "given connection pool timeout, expect timeout",
+			givenError:     errors.New(errPoolTimeout),
+			expectedReason: promred.ReasonTimeout,
+			expectedOk:     true,
+		},
+		{
+			name:       "given unrecognised error, expect no reason",
+			givenError: errors.New("fail"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualReason, actualOk := Reason(test.givenError)
+
+			if !cmp.Equal(actualReason, test.expectedReason) {
+				t.Fatal(cmp.Diff(actualReason, test.expectedReason))
+			}
+
+			if !cmp.Equal(actualOk, test.expectedOk) {
+				t.Fatal(cmp.Diff(actualOk, test.expectedOk))
+			}
+		})
+	}
+}
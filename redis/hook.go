@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jamieaitken/promred"
+)
+
+type callKey struct{}
+
+type call struct {
+	start time.Time
+	done  func()
+}
+
+var _ redis.Hook = Hook{}
+
+// Hook instruments every command of the client it is added to, via AddHook, leaving the client usable anywhere a
+// redis.Cmdable is. Operations are named after the command, e.g. zadd, or pipeline, and the invoker is read from the
+// context as set by promred.WithInvoker. Commands also covered by Redis share its operation names, e.g. Get, so they
+// are labelled the same whichever is used. A pipeline fails with the first error of its commands which counts as one.
+type Hook struct {
+	recorder *promred.Recorder
+}
+
+func NewHook(opts ...Option) Hook {
+	o := newOptions(opts)
+
+	return Hook{
+		recorder: promred.NewRecorder(family, o.recorder...),
+	}
+}
+
+func (h Hook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.start(ctx, operationFor(cmd)), nil
+}
+
+func (h Hook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.record(ctx, operationFor(cmd), cmd.Err())
+
+	return nil
+}
+
+func (h Hook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "pipeline"), nil
+}
+
+func (h Hook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+
+	for _, cmd := range cmds {
+		if h.recorder.IsError(cmd.Err()) {
+			err = cmd.Err()
+
+			break
+		}
+	}
+
+	h.record(ctx, "pipeline", err)
+
+	return nil
+}
+
+// operations maps the commands covered by Redis to its operation names.
+var operations = map[string]string{
+	"get":   "Get",
+	"hget":  "HGet",
+	"mget":  "MGet",
+	"mset":  "MSet",
+	"ping":  "Ping",
+	"set":   "Set",
+	"setex": "SetEX",
+}
+
+func operationFor(cmd redis.Cmder) string {
+	if operation, ok := operations[cmd.Name()]; ok {
+		return operation
+	}
+
+	return cmd.Name()
+}
+
+func (h Hook) start(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, callKey{}, call{
+		start: time.Now(),
+		done:  h.recorder.InFlight(h.recorder.Invoker(ctx), operation),
+	})
+}
+
+func (h Hook) record(ctx context.Context, operation string, err error) {
+	c, ok := ctx.Value(callKey{}).(call)
+	if !ok {
+		return
+	}
+
+	c.done()
+	h.recorder.Record(ctx, time.Since(c.start), err, h.recorder.Invoker(ctx), operation)
+}
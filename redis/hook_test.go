@@ -0,0 +1,253 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHook_AfterProcess(t *testing.T) {
+	failCmd := redis.NewStringCmd(context.Background(), "get", "key")
+	failCmd.SetErr(errors.New("fail"))
+
+	tests := []struct {
+		name                   string
+		givenCmd               redis.Cmder
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenCmd:               redis.NewStringCmd(context.Background(), "get", "key"),
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:                   "given failure, expect operation count to be 1 and error count to be 1",
+			givenCmd:               failCmd,
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := NewHook(WithRegisterer(reg))
+
+			ctx, err := h.BeforeProcess(promred.WithInvoker(context.Background(), "test"), test.givenCmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = h.AfterProcess(ctx, test.givenCmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "Get"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "redis_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "redis_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestHook_AfterProcessPipeline(t *testing.T) {
+	failCmd := redis.NewStatusCmd(context.Background(), "set", "key", "value")
+	failCmd.SetErr(errors.New("fail"))
+
+	nilCmd := redis.NewStringCmd(context.Background(), "get", "key")
+	nilCmd.SetErr(redis.Nil)
+
+	tests := []struct {
+		name                   string
+		givenCmds              []redis.Cmder
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name: "given success, expect operation count to be 1 and error count to be 0",
+			givenCmds: []redis.Cmder{
+				redis.NewStringCmd(context.Background(), "get", "key"),
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given one failed command, expect operation count to be 1 and error count to be 1",
+			givenCmds: []redis.Cmder{
+				redis.NewStringCmd(context.Background(), "get", "key"),
+				failCmd,
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+		{
+			name: "given a missing key before a failed command, expect operation count to be 1 and error count to be 1",
+			givenCmds: []redis.Cmder{
+				nilCmd,
+				failCmd,
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := NewHook(WithRegisterer(reg), WithDefaultInvoker("test"))
+
+			ctx, err := h.BeforeProcessPipeline(context.Background(), test.givenCmds)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = h.AfterProcessPipeline(ctx, test.givenCmds)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "pipeline"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "redis_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "redis_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestHook_OperationNames(t *testing.T) {
+	tests := []struct {
+		name              string
+		givenCmd          redis.Cmder
+		expectedOperation string
+	}{
+		{
+			name:              "given a command covered by Redis, expect its operation name",
+			givenCmd:          redis.NewStatusCmd(context.Background(), "setex", "key", 1, "value"),
+			expectedOperation: "SetEX",
+		},
+		{
+			name:              "given any other command, expect the command name",
+			givenCmd:          redis.NewIntCmd(context.Background(), "zadd", "key", 1, "member"),
+			expectedOperation: "zadd",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := NewHook(WithRegisterer(reg), WithDefaultInvoker("test"))
+
+			ctx, err := h.BeforeProcess(context.Background(), test.givenCmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = h.AfterProcess(ctx, test.givenCmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := testtool.GetCounterValue(reg, "redis_operation_total",
+				prometheus.Labels{"invoker": "test", "operation": test.expectedOperation})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actual, 1) {
+				t.Fatal(cmp.Diff(actual, 1))
+			}
+		})
+	}
+}
+
+func TestHook_WithInFlight(t *testing.T) {
+	tests := []struct {
+		name                string
+		givenCmd            redis.Cmder
+		expectedAfterBefore int
+		expectedAfterAfter  int
+	}{
+		{
+			name:                "given WithInFlight, expect the gauge to be 1 between the hooks and 0 after",
+			givenCmd:            redis.NewStringCmd(context.Background(), "get", "key"),
+			expectedAfterBefore: 1,
+			expectedAfterAfter:  0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			h := NewHook(WithRegisterer(reg), WithInFlight())
+
+			ctx, err := h.BeforeProcess(promred.WithInvoker(context.Background(), "test"), test.givenCmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "Get"}
+
+			actualAfterBefore, err := testtool.GetGaugeValue(reg, "redis_in_flight", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualAfterBefore, test.expectedAfterBefore) {
+				t.Fatal(cmp.Diff(actualAfterBefore, test.expectedAfterBefore))
+			}
+
+			err = h.AfterProcess(ctx, test.givenCmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actualAfterAfter, err := testtool.GetGaugeValue(reg, "redis_in_flight", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualAfterAfter, test.expectedAfterAfter) {
+				t.Fatal(cmp.Diff(actualAfterAfter, test.expectedAfterAfter))
+			}
+		})
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/jamieaitken/promred"
 )
 
 type redisProvider interface {
@@ -18,128 +18,100 @@ type redisProvider interface {
 	Ping(ctx context.Context) *redis.StatusCmd
 }
 
-var (
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
-)
-
-func init() {
-	operationCount = withRate()
-	errorCount = withError()
-	duration = withDuration()
-}
-
 type Redis struct {
-	provider       redisProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	provider redisProvider
+	recorder *promred.Recorder
 }
 
-func New(client redisProvider) Redis {
+func New(client redisProvider, opts ...Option) Redis {
+	o := newOptions(opts)
+
 	return Redis{
-		provider:       client,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		provider: client,
+		recorder: promred.NewRecorder(family, o.recorder...),
 	}
 }
 
 func (r Redis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, invoker string) *redis.StatusCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "Set"))
-	defer timer.ObserveDuration()
+	var cmd *redis.StatusCmd
 
-	r.operationCount.WithLabelValues(invoker, "Set").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "Set", func() error {
+		cmd = r.provider.Set(ctx, key, value, expiration)
 
-	cmd := r.provider.Set(ctx, key, value, expiration)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "Set").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
 
 func (r Redis) Get(ctx context.Context, key, invoker string) *redis.StringCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "Get"))
-	defer timer.ObserveDuration()
+	var cmd *redis.StringCmd
 
-	r.operationCount.WithLabelValues(invoker, "Get").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "Get", func() error {
+		cmd = r.provider.Get(ctx, key)
 
-	cmd := r.provider.Get(ctx, key)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "Get").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
 
 func (r Redis) HGet(ctx context.Context, key, field, invoker string) *redis.StringCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "HGet"))
-	defer timer.ObserveDuration()
+	var cmd *redis.StringCmd
 
-	r.operationCount.WithLabelValues(invoker, "HGet").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "HGet", func() error {
+		cmd = r.provider.HGet(ctx, key, field)
 
-	cmd := r.provider.HGet(ctx, key, field)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "HGet").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
 
 func (r Redis) MGet(ctx context.Context, keys []string, invoker string) *redis.SliceCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "MGet"))
-	defer timer.ObserveDuration()
+	var cmd *redis.SliceCmd
 
-	r.operationCount.WithLabelValues(invoker, "MGet").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "MGet", func() error {
+		cmd = r.provider.MGet(ctx, keys...)
 
-	cmd := r.provider.MGet(ctx, keys...)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "MGet").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
 
 func (r Redis) MSet(ctx context.Context, values []interface{}, invoker string) *redis.StatusCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "MSet"))
-	defer timer.ObserveDuration()
+	var cmd *redis.StatusCmd
 
-	r.operationCount.WithLabelValues(invoker, "MSet").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "MSet", func() error {
+		cmd = r.provider.MSet(ctx, values...)
 
-	cmd := r.provider.MSet(ctx, values...)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "MSet").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
 
 func (r Redis) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration, invoker string) *redis.StatusCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "SetEX"))
-	defer timer.ObserveDuration()
+	var cmd *redis.StatusCmd
 
-	r.operationCount.WithLabelValues(invoker, "SetEX").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "SetEX", func() error {
+		cmd = r.provider.SetEX(ctx, key, value, expiration)
 
-	cmd := r.provider.SetEX(ctx, key, value, expiration)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "SetEX").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
 
 func (r Redis) Ping(ctx context.Context, invoker string) *redis.StatusCmd {
-	timer := prometheus.NewTimer(r.duration.WithLabelValues(invoker, "Ping"))
-	defer timer.ObserveDuration()
+	var cmd *redis.StatusCmd
 
-	r.operationCount.WithLabelValues(invoker, "Ping").Inc()
+	_ = r.recorder.Observe(ctx, invoker, "Ping", func() error {
+		cmd = r.provider.Ping(ctx)
 
-	cmd := r.provider.Ping(ctx)
-	if cmd.Err() != nil {
-		r.errorCount.WithLabelValues(invoker, "Ping").Inc()
-	}
+		return cmd.Err()
+	})
 
 	return cmd
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/google/go-cmp/cmp"
 	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestRedis_Get(t *testing.T) {
@@ -44,7 +45,9 @@ func TestRedis_Get(t *testing.T) {
 
 			_ = r.Get(context.Background(), "", "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "Get")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "Get",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -53,7 +56,9 @@ func TestRedis_Get(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "Get")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "Get",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -98,7 +103,9 @@ func TestRedis_HGet(t *testing.T) {
 
 			_ = r.HGet(context.Background(), "", "", "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "HGet")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "HGet",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -107,7 +114,9 @@ func TestRedis_HGet(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "HGet")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "HGet",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -152,7 +161,9 @@ func TestRedis_MGet(t *testing.T) {
 
 			_ = r.MGet(context.Background(), nil, "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "MGet")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "MGet",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -161,7 +172,9 @@ func TestRedis_MGet(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "MGet")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "MGet",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -206,7 +219,9 @@ func TestRedis_MSet(t *testing.T) {
 
 			_ = r.MSet(context.Background(), nil, "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "MSet")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "MSet",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -215,7 +230,9 @@ func TestRedis_MSet(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "MSet")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "MSet",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -260,7 +277,9 @@ func TestRedis_Set(t *testing.T) {
 
 			_ = r.Set(context.Background(), "", "", time.Hour*1, "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "Set")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "Set",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -269,7 +288,9 @@ func TestRedis_Set(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "Set")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "Set",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -314,7 +335,9 @@ func TestRedis_SetEX(t *testing.T) {
 
 			_ = r.SetEX(context.Background(), "", "", time.Hour*1, "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "SetEX")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "SetEX",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -323,7 +346,9 @@ func TestRedis_SetEX(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "SetEX")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "SetEX",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -368,7 +393,9 @@ func TestRedis_Ping(t *testing.T) {
 
 			_ = r.Ping(context.Background(), "test")
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*r.operationCount, "test", "Ping")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "Ping",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -377,7 +404,9 @@ func TestRedis_Ping(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*r.errorCount, "test", "Ping")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "redis_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "Ping",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,38 @@
+package sns
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/jamieaitken/promred"
+)
+
+// Client matches the Publish method of *sns.Client, reading the invoker from the context as set by
+// promred.WithInvoker. It has none of its other methods, so to instrument every operation of a *sns.Client add
+// NewAPIOption to its APIOptions instead.
+type Client struct {
+	snsProvider snsProvider
+	recorder    *promred.Recorder
+}
+
+func NewClient(provider snsProvider, opts ...Option) Client {
+	o := newOptions(opts)
+
+	return Client{
+		snsProvider: provider,
+		recorder:    promred.NewRecorder(family, o.recorder...),
+	}
+}
+
+func (c Client) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	var out *sns.PublishOutput
+
+	err := c.recorder.ObserveContext(ctx, "Publish", func() error {
+		var err error
+		out, err = c.snsProvider.Publish(ctx, params, optFns...)
+
+		return err
+	})
+
+	return out, err
+}
@@ -0,0 +1,68 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestClient_Publish(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenSNS               mockSNS
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenSNS:               mockSNS{},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given failure, expect operation count to be 1 and error count to be 1",
+			givenSNS: mockSNS{
+				GivenError: errors.New("fail"),
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			c := NewClient(test.givenSNS, WithRegisterer(reg))
+
+			_, err := c.Publish(promred.WithInvoker(context.Background(), "test"), nil)
+			if !errors.Is(err, test.givenSNS.GivenError) {
+				t.Fatalf("expected %v, got %v", test.givenSNS.GivenError, err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "Publish"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "sns_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "sns_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package sns
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+	"github.com/jamieaitken/promred"
+)
+
+// Reason is the built-in promred.ReasonMapper for SNS, which maps the API error codes for throttling and missing
+// resources.
+func Reason(err error) (string, bool) {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "Throttled", "Throttling", "ThrottlingException", "KMSThrottling":
+		return promred.ReasonThrottled, true
+	case "NotFound", "ResourceNotFound":
+		return promred.ReasonNotFound, true
+	case "RequestTimeout", "RequestTimeoutException":
+		return promred.ReasonTimeout, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,54 @@
+package sns
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+)
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenError     error
+		expectedReason string
+		expectedOk     bool
+	}{
+		{
+			name:           "given throttled, expect throttled",
+			givenError:     fmt.Errorf("publish: %w", &types.ThrottledException{}),
+			expectedReason: promred.ReasonThrottled,
+			expectedOk:     true,
+		},
+		{
+			name:           "given topic not found, expect not_found",
+			givenError:     &types.NotFoundException{},
+			expectedReason: promred.ReasonNotFound,
+			expectedOk:     true,
+		},
+		{
+			name:       "given other API error, expect no reason",
+			givenError: &types.InvalidParameterException{},
+		},
+		{
+			name:       "given unrecognised error, expect no reason",
+			givenError: errors.New("fail"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualReason, actualOk := Reason(test.givenError)
+
+			if !cmp.Equal(actualReason, test.expectedReason) {
+				t.Fatal(cmp.Diff(actualReason, test.expectedReason))
+			}
+
+			if !cmp.Equal(actualOk, test.expectedOk) {
+				t.Fatal(cmp.Diff(actualOk, test.expectedOk))
+			}
+		})
+	}
+}
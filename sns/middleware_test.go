@@ -0,0 +1,98 @@
+package sns
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewAPIOption(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenStatusCode        int
+		givenBody              string
+		expectedError          bool
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenStatusCode:        http.StatusOK,
+			givenBody:              `<PublishResponse><PublishResult><MessageId>1</MessageId></PublishResult></PublishResponse>`,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:            "given failure, expect operation count to be 1 and error count to be 1",
+			givenStatusCode: http.StatusBadRequest,
+			givenBody: `<ErrorResponse><Error><Type>Sender</Type><Code>NotFound</Code>` +
+				`<Message>fail</Message></Error><RequestId>1</RequestId></ErrorResponse>`,
+			expectedError:          true,
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			c := sns.New(sns.Options{
+				Region:      "eu-west-1",
+				Credentials: aws.AnonymousCredentials{},
+				HTTPClient:  mockHTTPClient{GivenStatusCode: test.givenStatusCode, GivenBody: test.givenBody},
+				APIOptions:  []func(*middleware.Stack) error{NewAPIOption(WithRegisterer(reg))},
+			})
+
+			_, err := c.Publish(promred.WithInvoker(context.Background(), "test"), &sns.PublishInput{
+				Message:  aws.String("message"),
+				TopicArn: aws.String("arn:aws:sns:eu-west-1:1:topic"),
+			})
+			if !cmp.Equal(err != nil, test.expectedError) {
+				t.Fatalf("expected error %v, got %v", test.expectedError, err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "Publish"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "sns_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "sns_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+type mockHTTPClient struct {
+	GivenStatusCode int
+	GivenBody       string
+}
+
+func (m mockHTTPClient) Do(_ *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: m.GivenStatusCode,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(m.GivenBody)),
+	}, nil
+}
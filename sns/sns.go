@@ -4,52 +4,37 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/sns"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/jamieaitken/promred"
 )
 
-var (
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
-)
-
-func init() {
-	operationCount = withRate()
-	errorCount = withError()
-	duration = withDuration()
-}
-
 type snsProvider interface {
 	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
 }
 
 type SNS struct {
-	snsProvider    snsProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	snsProvider snsProvider
+	recorder    *promred.Recorder
 }
 
-func New(provider snsProvider) SNS {
+func New(provider snsProvider, opts ...Option) SNS {
+	o := newOptions(opts)
+
 	return SNS{
-		snsProvider:    provider,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		snsProvider: provider,
+		recorder:    promred.NewRecorder(family, o.recorder...),
 	}
 }
 
 func (s SNS) Publish(ctx context.Context, params *sns.PublishInput, optFns []func(*sns.Options),
 	invoker string) (*sns.PublishOutput, error) {
-	timer := prometheus.NewTimer(s.duration.WithLabelValues(invoker, "Publish"))
-	defer timer.ObserveDuration()
+	var out *sns.PublishOutput
 
-	s.operationCount.WithLabelValues(invoker, "Publish").Inc()
+	err := s.recorder.Observe(ctx, invoker, "Publish", func() error {
+		var err error
+		out, err = s.snsProvider.Publish(ctx, params, optFns...)
 
-	out, err := s.snsProvider.Publish(ctx, params, optFns...)
-	if err != nil {
-		s.errorCount.WithLabelValues(invoker, "Publish").Inc()
-	}
+		return err
+	})
 
 	return out, err
 }
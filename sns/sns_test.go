@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/google/go-cmp/cmp"
 	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestSNS_Publish_Success(t *testing.T) {
@@ -33,7 +34,9 @@ func TestSNS_Publish_Success(t *testing.T) {
 				t.Fatalf("expected nil, got %v", err)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*snsNew.operationCount, "test", "Publish")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sns_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "Publish",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -42,7 +45,9 @@ func TestSNS_Publish_Success(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*snsNew.errorCount, "test", "Publish")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sns_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "Publish",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -79,7 +84,9 @@ func TestSNS_Publish_Fail(t *testing.T) {
 				t.Fatalf("expected %v, got nil", test.givenSNS.GivenError)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*snsNew.operationCount, "test", "Publish")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sns_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "Publish",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -88,7 +95,9 @@ func TestSNS_Publish_Fail(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*snsNew.errorCount, "test", "Publish")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sns_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "Publish",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
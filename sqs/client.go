@@ -0,0 +1,53 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jamieaitken/promred"
+)
+
+// Client matches the ReceiveMessage and SendMessage methods of *sqs.Client, reading the invoker from the context as set
+// by promred.WithInvoker. It has none of its other methods, so to instrument every operation of a *sqs.Client add
+// NewAPIOption to its APIOptions instead.
+type Client struct {
+	provider sqsProvider
+	recorder *promred.Recorder
+}
+
+func NewClient(provider sqsProvider, opts ...Option) Client {
+	o := newOptions(opts)
+
+	return Client{
+		provider: provider,
+		recorder: promred.NewRecorder(family, o.recorder...),
+	}
+}
+
+func (c Client) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput,
+	optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	var out *sqs.ReceiveMessageOutput
+
+	err := c.recorder.ObserveContext(ctx, "ReceiveMessage", func() error {
+		var err error
+		out, err = c.provider.ReceiveMessage(ctx, params, optFns...)
+
+		return err
+	})
+
+	return out, err
+}
+
+func (c Client) SendMessage(ctx context.Context, params *sqs.SendMessageInput,
+	optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	var out *sqs.SendMessageOutput
+
+	err := c.recorder.ObserveContext(ctx, "SendMessage", func() error {
+		var err error
+		out, err = c.provider.SendMessage(ctx, params, optFns...)
+
+		return err
+	})
+
+	return out, err
+}
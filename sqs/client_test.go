@@ -0,0 +1,124 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestClient_ReceiveMessage(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenSQS               mockSQS
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenSQS:               mockSQS{},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given failure, expect operation count to be 1 and error count to be 1",
+			givenSQS: mockSQS{
+				GivenReceiveError: errors.New("fail"),
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			c := NewClient(test.givenSQS, WithRegisterer(reg))
+
+			_, err := c.ReceiveMessage(promred.WithInvoker(context.Background(), "test"), nil)
+			if !errors.Is(err, test.givenSQS.GivenReceiveError) {
+				t.Fatalf("expected %v, got %v", test.givenSQS.GivenReceiveError, err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "ReceiveMessage"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "sqs_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "sqs_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenSQS               mockSQS
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenSQS:               mockSQS{},
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name: "given failure, expect operation count to be 1 and error count to be 1",
+			givenSQS: mockSQS{
+				GivenSendError: errors.New("fail"),
+			},
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			c := NewClient(test.givenSQS, WithRegisterer(reg), WithDefaultInvoker("test"))
+
+			_, err := c.SendMessage(context.Background(), nil)
+			if !errors.Is(err, test.givenSQS.GivenSendError) {
+				t.Fatalf("expected %v, got %v", test.givenSQS.GivenSendError, err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "SendMessage"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "sqs_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "sqs_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
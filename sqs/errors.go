@@ -0,0 +1,28 @@
+package sqs
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+	"github.com/jamieaitken/promred"
+)
+
+// Reason is the built-in promred.ReasonMapper for SQS, which maps the API error codes for throttling and missing
+// resources.
+func Reason(err error) (string, bool) {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "RequestThrottled", "Throttling", "ThrottlingException", "KmsThrottled":
+		return promred.ReasonThrottled, true
+	case "AWS.SimpleQueueService.NonExistentQueue", "QueueDoesNotExist":
+		return promred.ReasonNotFound, true
+	case "RequestTimeout", "RequestTimeoutException":
+		return promred.ReasonTimeout, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,55 @@
+package sqs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+)
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenError     error
+		expectedReason string
+		expectedOk     bool
+	}{
+		{
+			name:           "given request throttled, expect throttled",
+			givenError:     fmt.Errorf("send: %w", &smithy.GenericAPIError{Code: "RequestThrottled"}),
+			expectedReason: promred.ReasonThrottled,
+			expectedOk:     true,
+		},
+		{
+			name:           "given queue does not exist, expect not_found",
+			givenError:     &types.QueueDoesNotExist{},
+			expectedReason: promred.ReasonNotFound,
+			expectedOk:     true,
+		},
+		{
+			name:       "given other API error, expect no reason",
+			givenError: &types.InvalidMessageContents{},
+		},
+		{
+			name:       "given unrecognised error, expect no reason",
+			givenError: errors.New("fail"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualReason, actualOk := Reason(test.givenError)
+
+			if !cmp.Equal(actualReason, test.expectedReason) {
+				t.Fatal(cmp.Diff(actualReason, test.expectedReason))
+			}
+
+			if !cmp.Equal(actualOk, test.expectedOk) {
+				t.Fatal(cmp.Diff(actualOk, test.expectedOk))
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package sqs
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/jamieaitken/promred"
+	"github.com/jamieaitken/promred/internal/awsapi"
+)
+
+// NewAPIOption returns an option which, added to the APIOptions of a *sqs.Client or the aws.Config it is created from,
+// instruments every operation of that client, leaving it usable anywhere a *sqs.Client is. Operations of other services
+// sharing the aws.Config are not recorded. Operations are named after the API operation, e.g. SendMessage, and the invoker is
+// read from the context as set by promred.WithInvoker.
+func NewAPIOption(opts ...Option) func(stack *middleware.Stack) error {
+	o := newOptions(opts)
+
+	recorder := promred.NewRecorder(family, o.recorder...)
+
+	return awsapi.NewAPIOption("promred/sqs", sqs.ServiceID, recorder)
+}
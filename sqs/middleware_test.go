@@ -0,0 +1,145 @@
+package sqs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jamieaitken/promred"
+	snsinstrumentation "github.com/jamieaitken/promred/sns"
+	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewAPIOption(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenStatusCode        int
+		givenBody              string
+		expectedError          bool
+		expectedOperationCount int
+		expectedErrorCount     int
+	}{
+		{
+			name:                   "given success, expect operation count to be 1 and error count to be 0",
+			givenStatusCode:        http.StatusOK,
+			givenBody:              `<DeleteMessageResponse><ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></DeleteMessageResponse>`,
+			expectedOperationCount: 1,
+			expectedErrorCount:     0,
+		},
+		{
+			name:            "given failure, expect operation count to be 1 and error count to be 1",
+			givenStatusCode: http.StatusBadRequest,
+			givenBody: `<ErrorResponse><Error><Type>Sender</Type><Code>AWS.SimpleQueueService.NonExistentQueue</Code>` +
+				`<Message>fail</Message></Error><RequestId>1</RequestId></ErrorResponse>`,
+			expectedError:          true,
+			expectedOperationCount: 1,
+			expectedErrorCount:     1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			c := sqs.New(sqs.Options{
+				Region:      "eu-west-1",
+				Credentials: aws.AnonymousCredentials{},
+				HTTPClient:  mockHTTPClient{GivenStatusCode: test.givenStatusCode, GivenBody: test.givenBody},
+				APIOptions:  []func(*middleware.Stack) error{NewAPIOption(WithRegisterer(reg))},
+			})
+
+			_, err := c.DeleteMessage(promred.WithInvoker(context.Background(), "test"), &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String("https://sqs.eu-west-1.amazonaws.com/1/queue"),
+				ReceiptHandle: aws.String("handle"),
+			})
+			if !cmp.Equal(err != nil, test.expectedError) {
+				t.Fatalf("expected error %v, got %v", test.expectedError, err)
+			}
+
+			labels := prometheus.Labels{"invoker": "test", "operation": "DeleteMessage"}
+
+			actualOperationCount, err := testtool.GetCounterValue(reg, "sqs_operation_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualOperationCount, test.expectedOperationCount) {
+				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
+			}
+
+			actualErrorCount, err := testtool.GetCounterValue(reg, "sqs_error_total", labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(actualErrorCount, test.expectedErrorCount) {
+				t.Fatal(cmp.Diff(actualErrorCount, test.expectedErrorCount))
+			}
+		})
+	}
+}
+
+func TestNewAPIOption_SharedWithOtherServices(t *testing.T) {
+	sqsReg := prometheus.NewRegistry()
+	snsReg := prometheus.NewRegistry()
+
+	c := sqs.New(sqs.Options{
+		Region:      "eu-west-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient: mockHTTPClient{
+			GivenStatusCode: http.StatusOK,
+			GivenBody:       `<DeleteMessageResponse><ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></DeleteMessageResponse>`,
+		},
+		APIOptions: []func(*middleware.Stack) error{
+			NewAPIOption(WithRegisterer(sqsReg)),
+			snsinstrumentation.NewAPIOption(snsinstrumentation.WithRegisterer(snsReg)),
+		},
+	})
+
+	_, err := c.DeleteMessage(promred.WithInvoker(context.Background(), "test"), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String("https://sqs.eu-west-1.amazonaws.com/1/queue"),
+		ReceiptHandle: aws.String("handle"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actualOperationCount, err := testtool.GetCounterValue(sqsReg, "sqs_operation_total",
+		prometheus.Labels{"invoker": "test", "operation": "DeleteMessage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualOperationCount, 1) {
+		t.Fatal(cmp.Diff(actualOperationCount, 1))
+	}
+
+	actualSNSSeries, err := testutil.GatherAndCount(snsReg, "sns_operation_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(actualSNSSeries, 0) {
+		t.Fatal(cmp.Diff(actualSNSSeries, 0))
+	}
+}
+
+type mockHTTPClient struct {
+	GivenStatusCode int
+	GivenBody       string
+}
+
+func (m mockHTTPClient) Do(_ *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: m.GivenStatusCode,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(m.GivenBody)),
+	}, nil
+}
@@ -4,68 +4,52 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/jamieaitken/promred"
 )
 
-var (
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
-)
-
-func init() {
-	operationCount = withRate()
-	errorCount = withError()
-	duration = withDuration()
-}
-
 type sqsProvider interface {
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
 }
 
 type SQS struct {
-	provider       sqsProvider
-	operationCount *prometheus.CounterVec
-	errorCount     *prometheus.CounterVec
-	duration       *prometheus.HistogramVec
+	provider sqsProvider
+	recorder *promred.Recorder
 }
 
-func New(provider sqsProvider) SQS {
+func New(provider sqsProvider, opts ...Option) SQS {
+	o := newOptions(opts)
+
 	return SQS{
-		provider:       provider,
-		operationCount: operationCount,
-		errorCount:     errorCount,
-		duration:       duration,
+		provider: provider,
+		recorder: promred.NewRecorder(family, o.recorder...),
 	}
 }
 
 func (s SQS) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns []func(*sqs.Options),
 	invoker string) (*sqs.ReceiveMessageOutput, error) {
-	timer := prometheus.NewTimer(s.duration.WithLabelValues(invoker, "ReceiveMessage"))
-	defer timer.ObserveDuration()
+	var out *sqs.ReceiveMessageOutput
 
-	s.operationCount.WithLabelValues(invoker, "ReceiveMessage").Inc()
+	err := s.recorder.Observe(ctx, invoker, "ReceiveMessage", func() error {
+		var err error
+		out, err = s.provider.ReceiveMessage(ctx, params, optFns...)
 
-	out, err := s.provider.ReceiveMessage(ctx, params, optFns...)
-	if err != nil {
-		s.errorCount.WithLabelValues(invoker, "ReceiveMessage").Inc()
-	}
+		return err
+	})
 
 	return out, err
 }
 
 func (s SQS) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns []func(*sqs.Options),
 	invoker string) (*sqs.SendMessageOutput, error) {
-	timer := prometheus.NewTimer(s.duration.WithLabelValues(invoker, "SendMessage"))
-	defer timer.ObserveDuration()
+	var out *sqs.SendMessageOutput
 
-	s.operationCount.WithLabelValues(invoker, "SendMessage").Inc()
+	err := s.recorder.Observe(ctx, invoker, "SendMessage", func() error {
+		var err error
+		out, err = s.provider.SendMessage(ctx, params, optFns...)
 
-	out, err := s.provider.SendMessage(ctx, params, optFns...)
-	if err != nil {
-		s.errorCount.WithLabelValues(invoker, "SendMessage").Inc()
-	}
+		return err
+	})
 
 	return out, err
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/google/go-cmp/cmp"
 	testtool "github.com/jamieaitken/promred/testing"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestSQS_ReceiveMessageWithContext_Success(t *testing.T) {
@@ -33,7 +34,9 @@ func TestSQS_ReceiveMessageWithContext_Success(t *testing.T) {
 				t.Fatalf("expected nil, got %v", err)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*s.operationCount, "test", "ReceiveMessage")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReceiveMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -42,7 +45,9 @@ func TestSQS_ReceiveMessageWithContext_Success(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*s.errorCount, "test", "ReceiveMessage")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReceiveMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -79,7 +84,9 @@ func TestSQS_ReceiveMessageWithContext_Fail(t *testing.T) {
 				t.Fatalf("expected %v, got nil", test.givenSQS.GivenReceiveError)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*s.operationCount, "test", "ReceiveMessage")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReceiveMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -88,7 +95,9 @@ func TestSQS_ReceiveMessageWithContext_Fail(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*s.errorCount, "test", "ReceiveMessage")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "ReceiveMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -123,7 +132,9 @@ func TestSQS_SendMessageWithContext_Success(t *testing.T) {
 				t.Fatalf("expected nil, got %v", err)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*s.operationCount, "test", "SendMessage")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "SendMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -132,7 +143,9 @@ func TestSQS_SendMessageWithContext_Success(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*s.errorCount, "test", "SendMessage")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "SendMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -169,7 +182,9 @@ func TestSQS_SendMessageWithContext_Fail(t *testing.T) {
 				t.Fatalf("expected %v, got nil", test.givenSQS.GivenSendError)
 			}
 
-			actualOperationCount, err := testtool.GetCounterVecValue(*s.operationCount, "test", "SendMessage")
+			actualOperationCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_operation_total", prometheus.Labels{
+				"invoker": "test", "operation": "SendMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -178,7 +193,9 @@ func TestSQS_SendMessageWithContext_Fail(t *testing.T) {
 				t.Fatal(cmp.Diff(actualOperationCount, test.expectedOperationCount))
 			}
 
-			actualErrorCount, err := testtool.GetCounterVecValue(*s.errorCount, "test", "SendMessage")
+			actualErrorCount, err := testtool.GetCounterValue(prometheus.DefaultGatherer, "sqs_error_total", prometheus.Labels{
+				"invoker": "test", "operation": "SendMessage",
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
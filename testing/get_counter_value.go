@@ -14,6 +14,42 @@ func GetCounterVecValue(vec prometheus.CounterVec, lvs ...string) (int, error) {
 	return getCounterValue(counter)
 }
 
+func GetCounterValue(gatherer prometheus.Gatherer, name string, labels prometheus.Labels) (int, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if hasLabels(metric, labels) {
+				return int(metric.GetCounter().GetValue()), nil
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+func hasLabels(metric *dto.Metric, labels prometheus.Labels) bool {
+	if len(metric.GetLabel()) != len(labels) {
+		return false
+	}
+
+	for _, label := range metric.GetLabel() {
+		value, ok := labels[label.GetName()]
+		if !ok || value != label.GetValue() {
+			return false
+		}
+	}
+
+	return true
+}
+
 func getCounterValue(counter prometheus.Counter) (int, error) {
 	dtoMetric := dto.Metric{}
 	err := counter.Write(&dtoMetric)
@@ -0,0 +1,45 @@
+package testing
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func GetExemplarTraceIDs(gatherer prometheus.Gatherer, name string) ([]string, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	var exemplars []*dto.Exemplar
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if metric.GetCounter().GetExemplar() != nil {
+				exemplars = append(exemplars, metric.GetCounter().GetExemplar())
+			}
+
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				if bucket.GetExemplar() != nil {
+					exemplars = append(exemplars, bucket.GetExemplar())
+				}
+			}
+		}
+	}
+
+	var traceIDs []string
+
+	for _, exemplar := range exemplars {
+		for _, label := range exemplar.GetLabel() {
+			if label.GetName() == "trace_id" {
+				traceIDs = append(traceIDs, label.GetValue())
+			}
+		}
+	}
+
+	return traceIDs, nil
+}
@@ -0,0 +1,26 @@
+package testing
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func GetGaugeValue(gatherer prometheus.Gatherer, name string, labels prometheus.Labels) (int, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if hasLabels(metric, labels) {
+				return int(metric.GetGauge().GetValue()), nil
+			}
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,25 @@
+package testing
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func GetHistogram(gatherer prometheus.Gatherer, name string) (*dto.Histogram, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, family := range families {
+		if family.GetName() != name || len(family.GetMetric()) == 0 {
+			continue
+		}
+
+		return family.GetMetric()[0].GetHistogram(), nil
+	}
+
+	return nil, fmt.Errorf("histogram %s not found", name)
+}
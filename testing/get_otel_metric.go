@@ -0,0 +1,65 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func GetOTelSumValue(reader sdkmetric.Reader, name string, attributes ...attribute.KeyValue) (float64, error) {
+	aggregation, err := getOTelAggregation(reader, name)
+	if err != nil {
+		return 0, err
+	}
+
+	sum, ok := aggregation.(metricdata.Sum[float64])
+	if !ok {
+		return 0, fmt.Errorf("%s is not a sum", name)
+	}
+
+	set := attribute.NewSet(attributes...)
+
+	for _, point := range sum.DataPoints {
+		if point.Attributes.Equals(&set) {
+			return point.Value, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func GetOTelHistogram(reader sdkmetric.Reader, name string) (metricdata.HistogramDataPoint[float64], error) {
+	aggregation, err := getOTelAggregation(reader, name)
+	if err != nil {
+		return metricdata.HistogramDataPoint[float64]{}, err
+	}
+
+	histogram, ok := aggregation.(metricdata.Histogram[float64])
+	if !ok || len(histogram.DataPoints) == 0 {
+		return metricdata.HistogramDataPoint[float64]{}, fmt.Errorf("histogram %s not found", name)
+	}
+
+	return histogram.DataPoints[0], nil
+}
+
+func getOTelAggregation(reader sdkmetric.Reader, name string) (metricdata.Aggregation, error) {
+	var rm metricdata.ResourceMetrics
+
+	err := reader.Collect(context.Background(), &rm)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m.Data, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("metric %s not found", name)
+}